@@ -2,24 +2,28 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"sort"
 	"time"
+	_ "time/tzdata" // timezone（例: Asia/Tokyo）をタイムゾーン情報のない環境でも解釈できるようにする
 
 	"github.com/ken344/rss-discord-notifier/internal/config"
+	"github.com/ken344/rss-discord-notifier/internal/digest"
 	"github.com/ken344/rss-discord-notifier/internal/discord"
 	"github.com/ken344/rss-discord-notifier/internal/feed"
+	"github.com/ken344/rss-discord-notifier/internal/filter"
+	"github.com/ken344/rss-discord-notifier/internal/httpclient"
 	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/internal/mention"
+	"github.com/ken344/rss-discord-notifier/internal/quota"
+	"github.com/ken344/rss-discord-notifier/internal/rules"
 	"github.com/ken344/rss-discord-notifier/internal/state"
 	"github.com/ken344/rss-discord-notifier/pkg/models"
 )
 
-const (
-	// 初回実行時に通知する記事数の上限
-	maxArticlesOnFirstRun = 5
-)
-
 func main() {
 	// エラーが発生した場合は、終了コード1で終了
 	if err := run(); err != nil {
@@ -53,6 +57,12 @@ func run() error {
 		"version", "1.0.0",
 		"feeds_count", len(appConfig.GetEnabledFeeds()))
 
+	// 設定から記事の振り分け・通知の処理を作成（設定の誤りは実行前に検出する）
+	pipe, err := newPipeline(appConfig.Config)
+	if err != nil {
+		return err
+	}
+
 	// 3. 状態管理マネージャーを初期化
 	logger.Info("状態を読み込んでいます...")
 	stateManager := state.NewManager(appConfig.StateFilePath)
@@ -60,100 +70,253 @@ func run() error {
 		return fmt.Errorf("状態の読み込みに失敗: %w", err)
 	}
 
-	// 初回実行かチェック
-	isFirstRun := stateManager.IsFirstRun()
-	if isFirstRun {
-		logger.Info("初回実行を検出しました（最新記事のみを通知します）")
+	// 4. RSSフィード取得器を初期化
+	// HTTPトランスポートはフィード取得とDiscord通知で共有する
+	transport, err := httpclient.NewTransport(appConfig.Config.HTTP)
+	if err != nil {
+		return fmt.Errorf("HTTPトランスポートの作成に失敗: %w", err)
 	}
 
-	// 4. RSSフィード取得器を初期化
 	timeout := time.Duration(appConfig.Config.Notification.TimeoutSeconds) * time.Second
 	fetcher := feed.NewFetcher(timeout)
+	fetcher.SetTransport(transport)
+	fetcher.SetConcurrency(
+		appConfig.Config.Notification.MaxConcurrency,
+		appConfig.Config.Notification.MaxConcurrencyPerHost)
+	fetcher.SetRetryPolicy(
+		*appConfig.Config.Notification.FetchMaxRetries,
+		time.Duration(appConfig.Config.Notification.FetchRetryMaxDelaySeconds)*time.Second)
+
+	// 前回保存したETag / Last-Modifiedで条件付きGETを行う
+	fetcher.SetCacheValidators(stateManager.GetCacheValidators())
+
+	// 状態を持たないフィード（初めて取得するフィード）は、最新N件のみを通知する
+	// 記事を既読として記録する前に判定する
+	enabledFeeds := appConfig.GetEnabledFeeds()
+	firstRunLimits := make(map[string]int)
+	for _, feedConfig := range enabledFeeds {
+		if !stateManager.HasFeedState(feedConfig.URL) {
+			firstRunLimits[feedConfig.URL] = feedConfig.GetFirstRunArticles(*appConfig.Config.Notification.FirstRunArticles)
+		}
+	}
+	if len(firstRunLimits) > 0 {
+		logger.Info("初めて取得するフィードを検出しました（最新記事のみを通知します）",
+			"feeds", len(firstRunLimits))
+	}
 
 	// 5. フィードから記事を取得
 	logger.Info("RSSフィードから記事を取得しています...")
-	enabledFeeds := appConfig.GetEnabledFeeds()
-	allArticles, err := fetcher.FetchAll(ctx, enabledFeeds)
+	fetchResult, err := fetcher.FetchAll(ctx, enabledFeeds)
 	if err != nil {
 		return fmt.Errorf("フィードの取得に失敗: %w", err)
 	}
+	allArticles := fetchResult.Articles
 
 	logger.Info("記事の取得が完了しました", "total_articles", len(allArticles))
 
+	// 通知済みの記事のうち、フィードから削除された記事を検出（on_remove を指定したフィードのみ）
+	removedArticles := detectRemovedArticles(fetchResult, enabledFeeds, stateManager, time.Now())
+	if len(removedArticles) > 0 {
+		logger.Info("フィードから削除された記事を検出しました", "removed_articles", len(removedArticles))
+	}
+
+	// フィードごとのキーワードフィルターを適用
+	// 除外した記事は既読として記録し、次回以降は再評価しない
+	filteredArticles, droppedCount := applyFilter(allArticles, pipe.articleFilter, stateManager)
+	if droppedCount > 0 {
+		logger.Info("フィルターで記事を除外しました", "dropped", droppedCount)
+	}
+
 	// 6. 新規記事をフィルタリング
-	newArticles := filterNewArticles(allArticles, stateManager)
+	newArticles := filterNewArticles(filteredArticles, stateManager)
 	logger.Info("新規記事を検出しました", "new_articles", len(newArticles))
 
-	// 初回実行の場合は、最新N件のみに制限
-	if isFirstRun && len(newArticles) > maxArticlesOnFirstRun {
-		logger.Info("初回実行のため、記事数を制限します",
-			"before", len(newArticles),
-			"after", maxArticlesOnFirstRun)
-		newArticles = limitArticles(newArticles, maxArticlesOnFirstRun)
+	// 通知済みの記事のうち、内容が更新された記事を検出（on_update を指定したフィードのみ）
+	updatedArticles := detectUpdatedArticles(filteredArticles, enabledFeeds, stateManager)
+	if len(updatedArticles) > 0 {
+		logger.Info("更新された記事を検出しました", "updated_articles", len(updatedArticles))
+	}
+
+	// 上限などで除外された記事も含めた新規記事（検証子の更新判定に使用）
+	candidateArticles := newArticles
+
+	// 公開日時の範囲外の記事を除外（古い記事は既読として記録、未来の記事は公開日時まで保留）
+	newArticles, tooOldCount, heldCount := applyAgePolicy(newArticles, pipe.agePolicy, stateManager, time.Now())
+	if tooOldCount > 0 || heldCount > 0 {
+		logger.Info("公開日時の範囲外の記事を除外しました",
+			"too_old", tooOldCount,
+			"held", heldCount)
 	}
 
-	// 通知する記事数の上限をチェック
+	// ルーティングルールで通知先を決定（破棄された記事は既読として記録）
+	newArticles, ruleDroppedCount := applyRoutingRules(newArticles, pipe.router, stateManager)
+	if ruleDroppedCount > 0 {
+		logger.Info("ルーティングルールで記事を破棄しました", "dropped", ruleDroppedCount)
+	}
+
+	// フィード・カテゴリ・キーワードごとのメンションを付与
+	for _, article := range newArticles {
+		pipe.mentioner.Apply(article)
+	}
+
+	// 初めて取得したフィードは、最新N件のみに制限（残りは既読として記録）
+	newArticles, seededCount := seedNewFeeds(newArticles, firstRunLimits, stateManager)
+
+	// delivery: digest のフィードの記事はダイジェストの待ちに追加（通知日時にまとめて通知）
+	newArticles, queuedCount := queueDigestArticles(newArticles, pipe.digestPolicy, stateManager)
+	if queuedCount > 0 {
+		logger.Info("ダイジェストの待ちに記事を追加しました", "queued", queuedCount)
+	}
+
+	// 通知する記事数の上限をチェック（上限を超えた記事は次回に延期）
 	maxArticles := appConfig.Config.Notification.MaxArticlesPerRun
+	deferredCount := 0
 	if len(newArticles) > maxArticles {
-		logger.Warn("記事数が上限を超えています。古い記事から制限します。",
+		logger.Warn("記事数が上限を超えています。超えた記事は次回に延期します。",
 			"count", len(newArticles),
-			"max", maxArticles)
-		newArticles = limitArticles(newArticles, maxArticles)
+			"max", maxArticles,
+			"quota_mode", appConfig.Config.Notification.QuotaMode)
+
+		allocator := quota.New(appConfig.Config.Notification.QuotaMode, enabledFeeds)
+		var deferredArticles []*models.Article
+		newArticles, deferredArticles = allocator.Allocate(newArticles, maxArticles)
+		deferredCount = len(deferredArticles)
+		logDeferredArticles(deferredArticles)
 	}
 
+	// 画像のない記事について、記事ページから画像を取得（fetch_og_image が有効なフィードのみ）
+	fetcher.EnrichImages(ctx, newArticles, enabledFeeds)
+
 	// 7. Discordに通知
+	// レート制限設定（通知間隔はDiscordのレート制限の情報がない場合のみ使用する）
+	rateLimit := time.Duration(appConfig.Config.Notification.RateLimitMs) * time.Millisecond
+	maxRateLimitWait := time.Duration(appConfig.Config.Notification.MaxRateLimitWaitSeconds) * time.Second
+
+	// Discord通知用のHTTPクライアント（フィード取得とコネクションプールを共有）
+	discordClient := transport.Client(30 * time.Second)
+
+	// Discordのレート制限の状態（Webhookごとのバケット）は実行全体で共有する
+	rateLimiter := discord.NewRateLimiter()
+
+	// Notifierを作成（Webhook URLごとに作成）
+	newNotifier := func(webhookURL string) *discord.Notifier {
+		notifier := discord.NewNotifier(webhookURL, rateLimit)
+		notifier.SetHTTPClient(discordClient)
+		notifier.SetRateLimiter(rateLimiter)
+		notifier.SetMaxRateLimitWait(maxRateLimitWait)
+		notifier.SetTemplates(pipe.templates)
+		return notifier
+	}
+
+	// 通知に成功した記事数
+	successCount := 0
 	if len(newArticles) > 0 {
 		logger.Info("Discordに通知を送信しています...", "count", len(newArticles))
 
-		// レート制限設定
-		rateLimit := time.Duration(appConfig.Config.Notification.RateLimitMs) * time.Millisecond
-
 		// 記事を通知（古い順に）
+		// ルーティングルールで複数の通知先が決定した記事は、通知先ごとに展開する
+		// 前回までの実行で通知に成功した通知先には再度通知しない
 		sortedArticles := sortArticlesByPublishedAt(newArticles)
+		routedArticles := skipDeliveredRoutes(expandRoutes(sortedArticles), appConfig.DiscordWebhookURL, stateManager)
 
-		// 記事ごとに適切なWebhook URLで通知
-		successCount := 0
-		for i, article := range sortedArticles {
-			// Webhook URLの決定（記事設定 > デフォルト）
-			webhookURL := article.WebhookURL
-			if webhookURL == "" {
-				webhookURL = appConfig.DiscordWebhookURL
-			}
+		// 記事ごとの最初に通知に成功した通知先の記事と、通知に失敗した通知先があるかどうか
+		sentArticles := make(map[string]*models.Article)
+		failedArticles := make(map[string]bool)
 
-			// Notifierを作成（Webhook URLごとに作成）
-			notifier := discord.NewNotifier(webhookURL, rateLimit)
+		// 通知先（Webhook URL）ごとにまとめ、embeds_per_message 件ずつ1つのメッセージで通知
+		embedsPerMessage := appConfig.Config.Notification.EmbedsPerMessage
+		messageCount := 0
+		for _, group := range groupByWebhook(routedArticles, appConfig.DiscordWebhookURL) {
+			notifier := newNotifier(group.webhookURL)
 
-			// 記事を送信
-			if err := notifier.SendArticle(ctx, article); err != nil {
-				logger.Error("記事の通知に失敗",
-					"title", article.Title,
-					"feed", article.FeedName,
-					"error", err)
-				continue
-			}
+			for _, messageArticles := range notifier.SplitIntoMessages(group.articles, embedsPerMessage) {
+				// レート制限対策（最初のメッセージ以外）
+				if messageCount > 0 {
+					if err := notifier.WaitInterval(ctx); err != nil {
+						return err
+					}
+				}
+				messageCount++
 
-			// 8. 通知済み記事を状態に記録
-			stateManager.MarkAsNotified(article)
-			successCount++
+				// 記事を送信
+				if err := notifier.SendBatch(ctx, messageArticles); err != nil {
+					for _, article := range messageArticles {
+						logger.Error("記事の通知に失敗",
+							"title", article.Title,
+							"feed", article.FeedName,
+							"error", err)
+						failedArticles[articleKey(article)] = true
+					}
+					continue
+				}
 
-			// レート制限対策（最後の記事以外）
-			if i < len(sortedArticles)-1 {
-				select {
-				case <-time.After(rateLimit):
-				case <-ctx.Done():
-					return ctx.Err()
+				// 通知に成功した通知先を記録し、次回の再通知の対象から除く
+				for _, article := range messageArticles {
+					stateManager.MarkRouteDelivered(article, routeKey(group.webhookURL))
+					if _, ok := sentArticles[articleKey(article)]; !ok {
+						sentArticles[articleKey(article)] = article
+					}
 				}
 			}
 		}
 
+		// 8. 通知済み記事を状態に記録（すべての通知先に通知できた記事のみ、通知先の数によらず1件として数える）
+		// 通知先を記録した記事はメッセージID（最初に通知に成功した通知先）で編集・削除する
+		for _, article := range sortedArticles {
+			key := articleKey(article)
+			sent, ok := sentArticles[key]
+			if !ok || failedArticles[key] {
+				continue
+			}
+			stateManager.MarkAsNotified(sent)
+			successCount++
+		}
+
 		logger.Info("通知が完了しました",
 			"total", len(sortedArticles),
+			"messages", messageCount,
 			"success", successCount,
 			"failed", len(sortedArticles)-successCount)
 	} else {
 		logger.Info("通知する新規記事がありません")
 	}
 
+	// 通知済みのメッセージは、通知に使用したWebhook（ルーティングルールの通知先を含む）で操作する
+	resolver := newWebhookResolver(appConfig.Config.Rules, enabledFeeds, appConfig.DiscordWebhookURL)
+
+	// 更新された記事を通知（通知済みのメッセージの編集または追加の通知）
+	updatedCount, err := notifyUpdatedArticles(ctx, updatedArticles, enabledFeeds, stateManager, resolver, newNotifier)
+	if err != nil {
+		return err
+	}
+
+	// フィードから削除された記事の通知を取り下げ（メッセージの削除または編集）
+	retractedCount, err := retractRemovedArticles(ctx, removedArticles, stateManager, resolver, newNotifier)
+	if err != nil {
+		return err
+	}
+
+	// 通知日時を迎えたダイジェストを通知
+	digestCount, err := sendDueDigests(ctx, pipe.digestPolicy, stateManager, appConfig, newNotifier)
+	if err != nil {
+		return err
+	}
+
+	// 条件付きGET用の検証子を状態に反映
+	// 未通知の記事が残っているフィードは、次回も全件取得できるよう更新しない
+	pendingFeeds := make(map[string]bool)
+	for _, article := range candidateArticles {
+		if !stateManager.IsArticleNotified(article.FeedURL, article.ID) {
+			pendingFeeds[article.FeedURL] = true
+		}
+	}
+	for feedURL, validators := range fetcher.CacheValidators() {
+		if pendingFeeds[feedURL] {
+			continue
+		}
+		stateManager.SetCacheValidators(feedURL, validators)
+	}
+
 	// 9. 統計情報を更新
 	duration := time.Since(startTime)
 	stateManager.UpdateStatistics(len(enabledFeeds), duration.Seconds())
@@ -167,14 +330,703 @@ func run() error {
 	// サマリーを表示
 	logger.Info("実行サマリー",
 		"feeds_checked", len(enabledFeeds),
+		"not_modified_feeds", fetchResult.NotModifiedCount,
+		"failed_feeds", fetchResult.FailedCount,
 		"total_articles", len(allArticles),
+		"filtered_articles", droppedCount,
+		"rule_dropped_articles", ruleDroppedCount,
+		"too_old_articles", tooOldCount,
+		"held_articles", heldCount,
+		"seeded_articles", seededCount,
+		"deferred_articles", deferredCount,
+		"queued_digest_articles", queuedCount,
+		"digest_articles", digestCount,
+		"updated_articles", updatedCount,
+		"retracted_articles", retractedCount,
 		"new_articles", len(newArticles),
-		"notified", len(newArticles),
+		"notified", successCount,
 		"duration_seconds", duration.Seconds())
 
 	return nil
 }
 
+// pipeline は、設定から作成する記事の振り分け・通知の処理
+type pipeline struct {
+	// articleFilter はフィードごとのキーワードフィルター
+	articleFilter *filter.Filter
+
+	// agePolicy は公開日時（max_age / since）による判定ポリシー
+	agePolicy *filter.AgePolicy
+
+	// router は記事の通知先を決定するルーティングルール
+	router *rules.Router
+
+	// mentioner はフィード・カテゴリ・キーワードごとのメンションの付与器
+	mentioner *mention.Mentioner
+
+	// digestPolicy はフィードごとの通知方法とダイジェストの通知日時
+	digestPolicy *digest.Policy
+
+	// templates はフィード・カテゴリごとの通知メッセージのテンプレート
+	templates *discord.TemplateSet
+}
+
+// newPipeline は、設定から記事の振り分け・通知の処理を作成する
+// 無効なフィードも含めたすべての設定から作成するため、設定の誤りはフィードの取得前に検出される
+func newPipeline(cfg *models.Config) (*pipeline, error) {
+	articleFilter, err := filter.New(cfg.Feeds)
+	if err != nil {
+		return nil, fmt.Errorf("フィルターの作成に失敗: %w", err)
+	}
+
+	agePolicy, err := filter.NewAgePolicy(cfg.Notification, cfg.Feeds)
+	if err != nil {
+		return nil, fmt.Errorf("公開日時の範囲の作成に失敗: %w", err)
+	}
+
+	router, err := rules.New(cfg.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("ルーティングルールの作成に失敗: %w", err)
+	}
+
+	mentioner, err := mention.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("メンションの設定の読み込みに失敗: %w", err)
+	}
+
+	digestPolicy, err := digest.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ダイジェストの設定の読み込みに失敗: %w", err)
+	}
+
+	templates, err := discord.NewTemplateSet(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの読み込みに失敗: %w", err)
+	}
+
+	return &pipeline{
+		articleFilter: articleFilter,
+		agePolicy:     agePolicy,
+		router:        router,
+		mentioner:     mentioner,
+		digestPolicy:  digestPolicy,
+		templates:     templates,
+	}, nil
+}
+
+// applyFilter は、フィードごとのキーワードフィルターを適用する
+// 除外した新規記事は通知せずに既読として記録し、除外した件数を返す
+func applyFilter(articles []*models.Article, articleFilter *filter.Filter, stateManager *state.Manager) ([]*models.Article, int) {
+	filtered := make([]*models.Article, 0, len(articles))
+	dropped := 0
+
+	for _, article := range articles {
+		// 無効な記事と記録済みの記事は評価しない（filterNewArticles で除外される）
+		if !article.IsValid() || stateManager.IsArticleNotified(article.FeedURL, article.ID) {
+			filtered = append(filtered, article)
+			continue
+		}
+
+		if articleFilter.Allow(article) {
+			filtered = append(filtered, article)
+			continue
+		}
+
+		logger.Debug("フィルターで記事を除外",
+			"title", article.Title,
+			"feed", article.FeedName)
+		stateManager.MarkAsSkipped(article)
+		dropped++
+	}
+
+	return filtered, dropped
+}
+
+// applyAgePolicy は、公開日時の範囲（max_age / since）で記事を絞り込む
+// 古い記事は通知せずに既読として記録し、公開日時が未来の記事は記録せずに次回以降へ保留する
+func applyAgePolicy(articles []*models.Article, agePolicy *filter.AgePolicy, stateManager *state.Manager, now time.Time) ([]*models.Article, int, int) {
+	inWindow := make([]*models.Article, 0, len(articles))
+	tooOld := 0
+	held := 0
+
+	for _, article := range articles {
+		switch agePolicy.Check(article, now) {
+		case filter.AgeTooOld:
+			logger.Debug("古い記事をスキップ",
+				"title", article.Title,
+				"feed", article.FeedName,
+				"published_at", article.PublishedAt)
+			stateManager.MarkAsSkipped(article)
+			tooOld++
+		case filter.AgeFuture:
+			logger.Debug("公開日時が未来の記事を保留",
+				"title", article.Title,
+				"feed", article.FeedName,
+				"published_at", article.PublishedAt)
+			held++
+		default:
+			inWindow = append(inWindow, article)
+		}
+	}
+
+	return inWindow, tooOld, held
+}
+
+// seedNewFeeds は、初めて取得したフィードの記事を最新N件に制限する
+// それ以外の記事は通知せずに既読として記録し、過去の記事がまとめて通知されることを防ぐ
+func seedNewFeeds(articles []*models.Article, firstRunLimits map[string]int, stateManager *state.Manager) ([]*models.Article, int) {
+	// 初めて取得したフィードの記事をフィードごとにまとめる
+	byFeed := make(map[string][]*models.Article)
+	for _, article := range articles {
+		if _, ok := firstRunLimits[article.FeedURL]; ok {
+			byFeed[article.FeedURL] = append(byFeed[article.FeedURL], article)
+		}
+	}
+
+	// フィードごとに最新N件を通知対象にする
+	notify := make(map[*models.Article]bool)
+	for feedURL, feedArticles := range byFeed {
+		limit := firstRunLimits[feedURL]
+		kept := limitArticles(feedArticles, limit)
+		for _, article := range kept {
+			notify[article] = true
+		}
+
+		logger.Info("初めて取得したフィードの記事を制限します",
+			"feed", feedArticles[0].FeedName,
+			"articles", len(feedArticles),
+			"notify", len(kept))
+	}
+
+	seeded := make([]*models.Article, 0, len(articles))
+	skipped := 0
+	for _, article := range articles {
+		if _, ok := firstRunLimits[article.FeedURL]; ok && !notify[article] {
+			stateManager.MarkAsSkipped(article)
+			skipped++
+			continue
+		}
+		seeded = append(seeded, article)
+	}
+
+	return seeded, skipped
+}
+
+// queueDigestArticles は、ダイジェストで通知するフィードの記事を待ちに追加する
+// 待ちに追加した記事は即時の通知対象から除き、追加した件数を返す
+// ダイジェストはフィードの通知先に通知するため、ルーティングルールの通知先・メンションは使用しない
+func queueDigestArticles(articles []*models.Article, digestPolicy *digest.Policy, stateManager *state.Manager) ([]*models.Article, int) {
+	immediate := make([]*models.Article, 0, len(articles))
+	queued := 0
+
+	for _, article := range articles {
+		if !digestPolicy.IsDigest(article.FeedURL) {
+			immediate = append(immediate, article)
+			continue
+		}
+
+		logger.Debug("記事をダイジェストの待ちに追加",
+			"title", article.Title,
+			"feed", article.FeedName)
+		stateManager.QueueForDigest(article)
+		queued++
+	}
+
+	return immediate, queued
+}
+
+// sendDueDigests は、通知日時を迎えたダイジェストの記事を通知先ごとにまとめて通知する
+// 通知に成功した記事は待ちから削除して通知済みとして記録し、通知した記事数を返す
+func sendDueDigests(ctx context.Context, digestPolicy *digest.Policy, stateManager *state.Manager, appConfig *config.AppConfig, newNotifier func(webhookURL string) *discord.Notifier) (int, error) {
+	now := time.Now()
+	due := digestPolicy.Due(stateManager.GetDigestQueue(), now)
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	logger.Info("ダイジェストを通知しています...", "articles", len(due))
+
+	// 通知先はフィード設定から決定する（待ちの記事にはWebhook URLを保存しない）
+	webhookURLs := make(map[string]string)
+	for _, feedConfig := range appConfig.Config.Feeds {
+		webhookURLs[feedConfig.URL] = feedConfig.WebhookURL
+	}
+
+	articles := make([]*models.Article, 0, len(due))
+	for _, pending := range due {
+		article := pending.ToArticle()
+		article.WebhookURL = webhookURLs[article.FeedURL]
+		articles = append(articles, article)
+	}
+
+	title := fmt.Sprintf("📚 ダイジェスト（%s）", now.In(digestPolicy.Location()).Format("2006-01-02"))
+	sent := 0
+	messageCount := 0
+	for _, group := range groupByWebhook(sortArticlesByPublishedAt(articles), appConfig.DiscordWebhookURL) {
+		notifier := newNotifier(group.webhookURL)
+
+		messages := notifier.SplitDigest(group.articles)
+		for i, messageArticles := range messages {
+			// レート制限対策（最初のメッセージ以外）
+			if messageCount > 0 {
+				if err := notifier.WaitInterval(ctx); err != nil {
+					return sent, err
+				}
+			}
+			messageCount++
+
+			messageTitle := title
+			if len(messages) > 1 {
+				messageTitle = fmt.Sprintf("%s %d/%d", title, i+1, len(messages))
+			}
+
+			// 失敗した記事は待ちに残し、次回の実行で再度通知する
+			if err := notifier.SendDigest(ctx, messageTitle, messageArticles); err != nil {
+				logger.Error("ダイジェストの通知に失敗",
+					"articles", len(messageArticles),
+					"error", err)
+				continue
+			}
+
+			for _, article := range messageArticles {
+				stateManager.MarkDigestAsNotified(article)
+			}
+			sent += len(messageArticles)
+		}
+	}
+
+	return sent, nil
+}
+
+// detectUpdatedArticles は、通知済みの記事のうちタイトル・説明文・更新日時が変わった記事を返す
+// on_update を指定したフィードの、個別に通知した記事のみを対象とする（既読として記録した記事やダイジェストの記事は除く）
+// 内容のハッシュを記録していない記事は、現在の内容を通知時の内容として記録する
+func detectUpdatedArticles(articles []*models.Article, feeds []*models.FeedConfig, stateManager *state.Manager) []*models.Article {
+	watched := make(map[string]bool)
+	for _, feedConfig := range feeds {
+		if feedConfig.GetOnUpdate() != models.UpdateActionIgnore {
+			watched[feedConfig.URL] = true
+		}
+	}
+	if len(watched) == 0 {
+		return nil
+	}
+
+	updated := make([]*models.Article, 0)
+	for _, article := range articles {
+		if !watched[article.FeedURL] || !article.IsValid() {
+			continue
+		}
+
+		notified := stateManager.GetNotifiedArticle(article.FeedURL, article.ID)
+		if notified == nil || notified.Skipped || notified.Digest || notified.Retracted {
+			continue
+		}
+
+		if notified.ContentHash == "" {
+			stateManager.UpdateNotifiedArticle(article)
+			continue
+		}
+
+		if notified.IsChanged(article) {
+			logger.Debug("記事の更新を検出",
+				"title", article.Title,
+				"feed", article.FeedName)
+			updated = append(updated, article)
+		}
+	}
+
+	return updated
+}
+
+// notifyUpdatedArticles は、更新された記事をフィードの on_update に従って通知する
+// 更新は元の記事を通知したWebhook（チャンネル）に通知し、通知先が設定から外れた記事は通知しない
+// edit は通知済みのメッセージを編集し、編集できない場合（複数の記事をまとめたメッセージ、
+// 編集の失敗）は reply と同じく更新を追加で通知する
+// 通知に成功した記事は通知済みの記録を更新し、通知した記事数を返す
+func notifyUpdatedArticles(ctx context.Context, articles []*models.Article, feeds []*models.FeedConfig, stateManager *state.Manager, resolver *webhookResolver, newNotifier func(webhookURL string) *discord.Notifier) (int, error) {
+	if len(articles) == 0 {
+		return 0, nil
+	}
+
+	feedConfigs := make(map[string]*models.FeedConfig)
+	for _, feedConfig := range feeds {
+		feedConfigs[feedConfig.URL] = feedConfig
+	}
+
+	logger.Info("更新された記事を通知しています...", "count", len(articles))
+
+	updated := 0
+	for i, article := range sortArticlesByPublishedAt(articles) {
+		feedConfig := feedConfigs[article.FeedURL]
+		notified := stateManager.GetNotifiedArticle(article.FeedURL, article.ID)
+		webhookURL, ok := resolver.Resolve(notified, feedConfig)
+		if !ok {
+			logger.Warn("通知に使用したWebhookが設定にないため、更新を通知しません",
+				"title", article.Title,
+				"feed", article.FeedName,
+				"webhook_id", notified.WebhookID)
+			continue
+		}
+		notifier := newNotifier(webhookURL)
+
+		// レート制限対策（最初のメッセージ以外）
+		if i > 0 {
+			if err := notifier.WaitInterval(ctx); err != nil {
+				return updated, err
+			}
+		}
+
+		edited := false
+		if feedConfig != nil && feedConfig.GetOnUpdate() == models.UpdateActionEdit && canEditMessage(notified, webhookURL, stateManager) {
+			if err := notifier.EditMessage(ctx, notified.MessageID, article); err != nil {
+				logger.Warn("メッセージの編集に失敗したため、更新を追加で通知します",
+					"title", article.Title,
+					"feed", article.FeedName,
+					"error", err)
+			} else {
+				edited = true
+			}
+		}
+
+		if !edited {
+			if err := notifier.SendUpdate(ctx, article); err != nil {
+				logger.Error("記事の更新の通知に失敗",
+					"title", article.Title,
+					"feed", article.FeedName,
+					"error", err)
+				continue
+			}
+		}
+
+		stateManager.UpdateNotifiedArticle(article)
+		updated++
+	}
+
+	return updated, nil
+}
+
+// canEditMessage は、通知済みのメッセージを編集・削除できるかチェックする
+// 1つの記事のみを含み、同じWebhookで投稿したメッセージのみ編集・削除できる
+func canEditMessage(notified *models.NotifiedArticle, webhookURL string, stateManager *state.Manager) bool {
+	if notified == nil || notified.MessageID == "" {
+		return false
+	}
+	if stateManager.IsMessageShared(notified.MessageID) {
+		return false
+	}
+	return notified.WebhookID == "" || notified.WebhookID == discord.WebhookID(webhookURL)
+}
+
+// webhookResolver は、通知済みのメッセージを投稿したWebhookのURLを求める構造体
+// 状態ファイルにはWebhookのIDのみを保存するため、設定されたWebhook URL（フィード・
+// ルーティングルール・デフォルト）からIDが一致するものを探す
+type webhookResolver struct {
+	// byID はWebhookのIDごとのWebhook URL
+	byID map[string]string
+
+	// defaultWebhookURL はデフォルトのWebhook URL
+	defaultWebhookURL string
+}
+
+// newWebhookResolver は、設定されたWebhook URLから新しいwebhookResolverを作成する
+func newWebhookResolver(routingRules []*models.RoutingRule, feeds []*models.FeedConfig, defaultWebhookURL string) *webhookResolver {
+	r := &webhookResolver{
+		byID:              make(map[string]string),
+		defaultWebhookURL: defaultWebhookURL,
+	}
+
+	urls := []string{defaultWebhookURL}
+	for _, feedConfig := range feeds {
+		urls = append(urls, feedConfig.WebhookURL)
+	}
+	for _, rule := range routingRules {
+		urls = append(urls, rule.WebhookURL)
+	}
+
+	for _, webhookURL := range urls {
+		id := discord.WebhookID(webhookURL)
+		if id == "" {
+			continue
+		}
+		if _, ok := r.byID[id]; !ok {
+			r.byID[id] = webhookURL
+		}
+	}
+
+	return r
+}
+
+// Resolve は、通知済みのメッセージを投稿したWebhookのURLを返す
+// フィードのWebhook URL（なければデフォルト）のIDが一致する場合はそれを優先する（スレッドの指定を維持するため）
+// WebhookのIDを記録していない記事はフィードのWebhook URLを返し、IDが設定にない場合は false を返す
+func (r *webhookResolver) Resolve(notified *models.NotifiedArticle, feedConfig *models.FeedConfig) (string, bool) {
+	webhookURL := r.defaultWebhookURL
+	if feedConfig != nil && feedConfig.WebhookURL != "" {
+		webhookURL = feedConfig.WebhookURL
+	}
+
+	if notified == nil || notified.WebhookID == "" || notified.WebhookID == discord.WebhookID(webhookURL) {
+		return webhookURL, true
+	}
+
+	webhookURL, ok := r.byID[notified.WebhookID]
+	return webhookURL, ok
+}
+
+// removedArticle は、フィードから削除された通知済みの記事
+type removedArticle struct {
+	feed     *models.FeedConfig
+	notified *models.NotifiedArticle
+}
+
+// detectRemovedArticles は、通知から on_remove_within_hours 以内の記事のうち、フィードから削除された記事を返す
+// 内容を取得できたフィード（未更新・取得に失敗したフィードを除く）のみを対象とし、
+// 記事が1件もない場合や、新しい記事に押し出されてフィードから外れた記事は削除とみなさない
+func detectRemovedArticles(fetchResult *feed.FetchResult, feeds []*models.FeedConfig, stateManager *state.Manager, now time.Time) []*removedArticle {
+	currentIDs := make(map[string]map[string]bool)
+	oldest := make(map[string]time.Time)
+	for _, article := range fetchResult.Articles {
+		if currentIDs[article.FeedURL] == nil {
+			currentIDs[article.FeedURL] = make(map[string]bool)
+		}
+		currentIDs[article.FeedURL][article.ID] = true
+
+		if t, ok := oldest[article.FeedURL]; !ok || article.PublishedAt.Before(t) {
+			oldest[article.FeedURL] = article.PublishedAt
+		}
+	}
+
+	removed := make([]*removedArticle, 0)
+	for _, feedConfig := range feeds {
+		if feedConfig.GetOnRemove() == models.RemoveActionIgnore || !fetchResult.FetchedFeeds[feedConfig.URL] {
+			continue
+		}
+		if !stateManager.HasFeedState(feedConfig.URL) {
+			continue
+		}
+
+		current := currentIDs[feedConfig.URL]
+		if len(current) == 0 {
+			logger.Debug("記事のないフィードは削除の判定をしません", "feed", feedConfig.Name)
+			continue
+		}
+
+		within := feedConfig.GetOnRemoveWithin()
+		for _, notified := range stateManager.GetFeedState(feedConfig.URL).NotifiedArticles {
+			if notified.Skipped || notified.Digest || notified.Retracted || notified.MessageID == "" {
+				continue
+			}
+			if current[notified.ID] || now.Sub(notified.NotifiedAt) > within {
+				continue
+			}
+			if notified.PublishedAt.Before(oldest[feedConfig.URL]) {
+				continue
+			}
+
+			logger.Debug("フィードから削除された記事を検出",
+				"title", notified.Title,
+				"feed", feedConfig.Name)
+			removed = append(removed, &removedArticle{feed: feedConfig, notified: notified})
+		}
+	}
+
+	return removed
+}
+
+// retractRemovedArticles は、フィードから削除された記事の通知をフィードの on_remove に従って取り下げる
+// delete はメッセージを削除し、edit は取り下げの表示に編集する
+// メッセージは通知に使用したWebhookで操作し、複数の記事をまとめたメッセージや通知先が設定から外れたメッセージは取り下げない
+// 取り下げに成功した記事は記録し、取り下げた記事数を返す
+func retractRemovedArticles(ctx context.Context, removed []*removedArticle, stateManager *state.Manager, resolver *webhookResolver, newNotifier func(webhookURL string) *discord.Notifier) (int, error) {
+	if len(removed) == 0 {
+		return 0, nil
+	}
+
+	logger.Info("削除された記事の通知を取り下げています...", "count", len(removed))
+
+	retracted := 0
+	requestCount := 0
+	for _, r := range removed {
+		webhookURL, ok := resolver.Resolve(r.notified, r.feed)
+		if !ok {
+			logger.Warn("通知に使用したWebhookが設定にないため、取り下げません",
+				"title", r.notified.Title,
+				"feed", r.feed.Name,
+				"webhook_id", r.notified.WebhookID)
+			continue
+		}
+
+		if !canEditMessage(r.notified, webhookURL, stateManager) {
+			logger.Info("他の記事と同じメッセージで通知したため、取り下げません",
+				"title", r.notified.Title,
+				"feed", r.feed.Name)
+			continue
+		}
+
+		notifier := newNotifier(webhookURL)
+
+		// レート制限対策（最初のメッセージ以外）
+		if requestCount > 0 {
+			if err := notifier.WaitInterval(ctx); err != nil {
+				return retracted, err
+			}
+		}
+		requestCount++
+
+		var err error
+		if r.feed.GetOnRemove() == models.RemoveActionDelete {
+			err = notifier.DeleteMessage(ctx, r.notified.MessageID)
+		} else {
+			err = notifier.RetractMessage(ctx, r.notified.MessageID, r.notified.Title)
+		}
+		if err != nil {
+			logger.Error("削除された記事の通知の取り下げに失敗",
+				"title", r.notified.Title,
+				"feed", r.feed.Name,
+				"error", err)
+			continue
+		}
+
+		stateManager.MarkAsRetracted(r.feed.URL, r.notified.ID)
+		retracted++
+	}
+
+	return retracted, nil
+}
+
+// applyRoutingRules は、ルーティングルールを適用して記事の通知先を決定する
+// 破棄された記事は通知せずに既読として記録し、破棄した件数を返す
+func applyRoutingRules(articles []*models.Article, router *rules.Router, stateManager *state.Manager) ([]*models.Article, int) {
+	routed := make([]*models.Article, 0, len(articles))
+	dropped := 0
+
+	for _, article := range articles {
+		if !router.Route(article) {
+			stateManager.MarkAsSkipped(article)
+			dropped++
+			continue
+		}
+		routed = append(routed, article)
+	}
+
+	return routed, dropped
+}
+
+// expandRoutes は、記事を通知先ごとに展開する
+// 記事のメンション（フィード・カテゴリ・キーワード）はすべての通知先に付与する
+// 通知先が決定していない記事はそのまま返す
+func expandRoutes(articles []*models.Article) []*models.Article {
+	expanded := make([]*models.Article, 0, len(articles))
+
+	for _, article := range articles {
+		if len(article.Routes) == 0 {
+			expanded = append(expanded, article)
+			continue
+		}
+
+		for _, route := range article.Routes {
+			routed := *article
+			if route.WebhookURL != "" {
+				routed.WebhookURL = route.WebhookURL
+			}
+			routed.Mentions = mention.Merge(article.Mentions, route.Mentions)
+			routed.Routes = nil
+			expanded = append(expanded, &routed)
+		}
+	}
+
+	return expanded
+}
+
+// skipDeliveredRoutes は、前回までの実行で通知に成功した通知先への記事を除く
+// 一部の通知先への通知に失敗した記事は、失敗した通知先にのみ再通知する
+func skipDeliveredRoutes(articles []*models.Article, defaultWebhookURL string, stateManager *state.Manager) []*models.Article {
+	pending := make([]*models.Article, 0, len(articles))
+
+	for _, article := range articles {
+		webhookURL := article.WebhookURL
+		if webhookURL == "" {
+			webhookURL = defaultWebhookURL
+		}
+
+		if stateManager.IsRouteDelivered(article.FeedURL, article.ID, routeKey(webhookURL)) {
+			logger.Debug("通知済みの通知先をスキップ",
+				"title", article.Title,
+				"feed", article.FeedName,
+				"webhook_id", discord.WebhookID(webhookURL))
+			continue
+		}
+		pending = append(pending, article)
+	}
+
+	return pending
+}
+
+// routeKey は、通知先を状態に記録するためのキー（Webhook URLのトークンは保存しない）
+// DiscordのWebhook URLではWebhookのID、それ以外ではURLのハッシュを使用する
+func routeKey(webhookURL string) string {
+	if id := discord.WebhookID(webhookURL); id != "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(webhookURL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// articleKey は、通知先ごとに展開した記事を元の記事ごとにまとめるためのキー
+func articleKey(article *models.Article) string {
+	return article.FeedURL + "\n" + article.ID
+}
+
+// logDeferredArticles は、上限により次回に延期した記事をフィードごとにログ出力する
+func logDeferredArticles(articles []*models.Article) {
+	countByFeed := make(map[string]int)
+	for _, article := range articles {
+		logger.Info("上限により記事を次回に延期",
+			"title", article.Title,
+			"feed", article.FeedName,
+			"published_at", article.PublishedAt)
+		countByFeed[article.FeedName]++
+	}
+
+	for feedName, count := range countByFeed {
+		logger.Info("フィードの延期した記事数",
+			"feed", feedName,
+			"deferred", count)
+	}
+}
+
+// webhookGroup は、同じWebhook URLに通知する記事のまとまり
+type webhookGroup struct {
+	webhookURL string
+	articles   []*models.Article
+}
+
+// groupByWebhook は、記事を通知先のWebhook URLごとにまとめる
+// 記事の順序と、Webhook URLが最初に現れた順序を維持する
+func groupByWebhook(articles []*models.Article, defaultWebhookURL string) []*webhookGroup {
+	groups := make([]*webhookGroup, 0)
+	byURL := make(map[string]*webhookGroup)
+
+	for _, article := range articles {
+		// Webhook URLの決定（記事設定 > デフォルト）
+		webhookURL := article.WebhookURL
+		if webhookURL == "" {
+			webhookURL = defaultWebhookURL
+		}
+
+		group, ok := byURL[webhookURL]
+		if !ok {
+			group = &webhookGroup{webhookURL: webhookURL}
+			byURL[webhookURL] = group
+			groups = append(groups, group)
+		}
+		group.articles = append(group.articles, article)
+	}
+
+	return groups
+}
+
 // filterNewArticles は、新規記事のみをフィルタリングする
 func filterNewArticles(articles []*models.Article, stateManager *state.Manager) []*models.Article {
 	newArticles := make([]*models.Article, 0)
@@ -3,17 +3,17 @@ module github.com/ken344/rss-discord-notifier
 go 1.21
 
 require (
+	github.com/PuerkitoBio/goquery v1.8.0
 	github.com/mmcdole/gofeed v1.3.0
+	golang.org/x/net v0.4.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/PuerkitoBio/goquery v1.8.0 // indirect
 	github.com/andybalholm/cascadia v1.3.1 // indirect
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/mmcdole/goxpp v1.1.1-0.20240225020742-a0c311522b23 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
-	golang.org/x/net v0.4.0 // indirect
 	golang.org/x/text v0.5.0 // indirect
 )
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/ken344/rss-discord-notifier/pkg/models"
 	"gopkg.in/yaml.v3"
@@ -70,16 +71,75 @@ func loadConfigFile(filePath string) (*models.Config, error) {
 		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	// 各フィードのWebhook URLの環境変数を展開
-	for i := range config.Feeds {
-		if config.Feeds[i].WebhookURL != "" {
-			config.Feeds[i].WebhookURL = ExpandEnvVars(config.Feeds[i].WebhookURL)
+	// テンプレートファイルを読み込む（設定ファイルからの相対パス）
+	for name, tmpl := range config.Templates {
+		if err := loadTemplateFile(tmpl, filepath.Dir(filePath)); err != nil {
+			return nil, fmt.Errorf("failed to load template %s: %w", name, err)
 		}
 	}
 
+	// 各フィードの環境変数を展開
+	for _, feed := range config.Feeds {
+		expandFeedEnvVars(feed)
+	}
+
+	// ルーティングルールの通知先の環境変数を展開
+	for _, rule := range config.Rules {
+		rule.WebhookURL = ExpandEnvVars(rule.WebhookURL)
+	}
+
+	// プロキシURLの環境変数を展開（認証情報を含む場合があるため）
+	if config.HTTP != nil {
+		config.HTTP.ProxyURL = ExpandEnvVars(config.HTTP.ProxyURL)
+	}
+
 	return &config, nil
 }
 
+// loadTemplateFile は、テンプレートの file に指定されたYAMLファイルを読み込み、未指定の項目を補完する
+// 設定ファイルに直接指定した項目はファイルの内容より優先される
+func loadTemplateFile(tmpl *models.MessageTemplate, baseDir string) error {
+	if tmpl == nil || tmpl.File == "" {
+		return nil
+	}
+
+	path := tmpl.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	var fileTemplate models.MessageTemplate
+	if err := yaml.Unmarshal(data, &fileTemplate); err != nil {
+		return fmt.Errorf("failed to parse YAML %s: %w", path, err)
+	}
+
+	tmpl.Merge(&fileTemplate)
+	return nil
+}
+
+// expandFeedEnvVars は、フィード設定内の環境変数参照を展開する
+// 対象は Webhook URL・User-Agent・HTTPヘッダー・認証情報
+func expandFeedEnvVars(feed *models.FeedConfig) {
+	feed.WebhookURL = ExpandEnvVars(feed.WebhookURL)
+	feed.UserAgent = ExpandEnvVars(feed.UserAgent)
+
+	for name, value := range feed.Headers {
+		feed.Headers[name] = ExpandEnvVars(value)
+	}
+
+	if feed.Auth != nil {
+		feed.Auth.BearerToken = ExpandEnvVars(feed.Auth.BearerToken)
+		feed.Auth.Username = ExpandEnvVars(feed.Auth.Username)
+		feed.Auth.Password = ExpandEnvVars(feed.Auth.Password)
+		feed.Auth.Cookie = ExpandEnvVars(feed.Auth.Cookie)
+	}
+}
+
 // Validate は、設定が有効かチェックする
 func (a *AppConfig) Validate() error {
 	// Discord Webhook URLは必須
@@ -103,6 +163,44 @@ func (a *AppConfig) Validate() error {
 		if !feed.IsValid() {
 			return fmt.Errorf("feed %d (%s) is invalid", i, feed.Name)
 		}
+		if feed.Auth != nil {
+			if err := feed.Auth.Validate(); err != nil {
+				return fmt.Errorf("feed %d (%s) has invalid auth: %w", i, feed.Name, err)
+			}
+		}
+		for _, rule := range feed.Include {
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("feed %d (%s) has invalid include filter: %w", i, feed.Name, err)
+			}
+		}
+		for _, rule := range feed.Exclude {
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("feed %d (%s) has invalid exclude filter: %w", i, feed.Name, err)
+			}
+		}
+		if err := validateMentions(feed.Mentions); err != nil {
+			return fmt.Errorf("feed %d (%s) has invalid mentions: %w", i, feed.Name, err)
+		}
+	}
+
+	// ルーティングルールをチェック（条件式の構文は rules.New で検証する）
+	for i, rule := range a.Config.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d (%s) is invalid: %w", i, rule.DisplayName(), err)
+		}
+	}
+
+	// メンションの設定をチェック（カテゴリ・すべてのフィード）
+	for name, category := range a.Config.Categories {
+		if category == nil {
+			continue
+		}
+		if err := validateMentions(category.Mentions); err != nil {
+			return fmt.Errorf("category %s has invalid mentions: %w", name, err)
+		}
+	}
+	if err := validateMentions(a.Config.Mentions); err != nil {
+		return fmt.Errorf("invalid mentions: %w", err)
 	}
 
 	// ログレベルのバリデーション
@@ -119,6 +217,19 @@ func (a *AppConfig) Validate() error {
 	return nil
 }
 
+// validateMentions は、メンションの設定をチェックする
+func validateMentions(configs []*models.MentionConfig) error {
+	for i, mc := range configs {
+		if mc == nil {
+			continue
+		}
+		if err := mc.Validate(); err != nil {
+			return fmt.Errorf("mention %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
 // GetEnabledFeeds は、有効なフィードのリストを返す
 func (a *AppConfig) GetEnabledFeeds() []*models.FeedConfig {
 	return a.Config.GetEnabledFeeds()
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ken344/rss-discord-notifier/pkg/models"
@@ -131,6 +132,167 @@ func TestAppConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "セレクターのないHTMLフィード",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:    "Changelog",
+							URL:     "https://example.com/changelog",
+							Type:    models.FeedTypeHTML,
+							Enabled: true,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効なon_update",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:     "Advisories",
+							URL:      "https://example.com/advisories.xml",
+							OnUpdate: "delete",
+							Enabled:  true,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効なon_remove",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:     "Advisories",
+							URL:      "https://example.com/advisories.xml",
+							OnRemove: "hide",
+							Enabled:  true,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効なロールIDのメンション",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:     "Advisories",
+							URL:      "https://example.com/advisories.xml",
+							Mentions: []*models.MentionConfig{{Roles: []string{"on-call"}}},
+							Enabled:  true,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効化したフィードの無効なメンション",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:    "Test Feed",
+							URL:     "https://example.com/feed",
+							Enabled: true,
+						},
+						{
+							Name:     "Paused Feed",
+							URL:      "https://example.com/paused.xml",
+							Mentions: []*models.MentionConfig{{Users: []string{"alice"}}},
+							Enabled:  false,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効なカテゴリのメンション",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:    "Test Feed",
+							URL:     "https://example.com/feed",
+							Enabled: true,
+						},
+					},
+					Categories: map[string]*models.CategoryConfig{
+						"Security": {Mentions: []*models.MentionConfig{{Roles: []string{"<@&100>"}}}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効な正規表現のフィルター",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:    "Hacker News",
+							URL:     "https://hnrss.org/frontpage",
+							Enabled: true,
+							Exclude: []*models.FilterRule{{Patterns: []string{"("}}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "無効なメンションのルーティングルール",
+			config: &AppConfig{
+				DiscordWebhookURL: "https://discord.com/api/webhooks/test",
+				LogLevel:          "INFO",
+				Config: &models.Config{
+					Version: "1.0",
+					Feeds: []*models.FeedConfig{
+						{
+							Name:    "Test Feed",
+							URL:     "https://example.com/feed",
+							Enabled: true,
+						},
+					},
+					Rules: []*models.RoutingRule{
+						{When: `category == "Tech"`, Mention: "@oncall"},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name:    "正常な設定",
 			config:  minimalConfig,
@@ -147,3 +309,67 @@ func TestAppConfig_Validate(t *testing.T) {
 		})
 	}
 }
+
+// TestExpandFeedEnvVars は、フィード設定内の環境変数展開をテストする
+func TestExpandFeedEnvVars(t *testing.T) {
+	os.Setenv("TEST_FEED_TOKEN", "secret-token")
+	defer os.Unsetenv("TEST_FEED_TOKEN")
+
+	feed := &models.FeedConfig{
+		Name:      "Private Feed",
+		URL:       "https://gitlab.example.com/activity.atom",
+		UserAgent: "notifier (${TEST_FEED_TOKEN})",
+		Headers: map[string]string{
+			"PRIVATE-TOKEN": "${TEST_FEED_TOKEN}",
+		},
+		Auth: &models.FeedAuth{
+			BearerToken: "${TEST_FEED_TOKEN}",
+			Cookie:      "session=${TEST_FEED_TOKEN}",
+		},
+	}
+
+	expandFeedEnvVars(feed)
+
+	if feed.Headers["PRIVATE-TOKEN"] != "secret-token" {
+		t.Errorf("Headers[PRIVATE-TOKEN] = %v, want secret-token", feed.Headers["PRIVATE-TOKEN"])
+	}
+	if feed.Auth.BearerToken != "secret-token" {
+		t.Errorf("Auth.BearerToken = %v, want secret-token", feed.Auth.BearerToken)
+	}
+	if feed.Auth.Cookie != "session=secret-token" {
+		t.Errorf("Auth.Cookie = %v, want session=secret-token", feed.Auth.Cookie)
+	}
+	if feed.UserAgent != "notifier (secret-token)" {
+		t.Errorf("UserAgent = %v, want notifier (secret-token)", feed.UserAgent)
+	}
+}
+
+// TestLoadTemplateFile は、テンプレートファイルの読み込みをテストする
+func TestLoadTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	content := "title: \"{{.FeedName}}: {{.Title}}\"\nfooter: \"from file\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "compact.yaml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write template file: %v", err)
+	}
+
+	// 設定ファイルに直接指定した項目が優先される
+	tmpl := &models.MessageTemplate{
+		File:   "compact.yaml",
+		Footer: "from config",
+	}
+	if err := loadTemplateFile(tmpl, dir); err != nil {
+		t.Fatalf("loadTemplateFile() error = %v", err)
+	}
+
+	if tmpl.Title != "{{.FeedName}}: {{.Title}}" {
+		t.Errorf("Title = %q, want value from file", tmpl.Title)
+	}
+	if tmpl.Footer != "from config" {
+		t.Errorf("Footer = %q, want %q", tmpl.Footer, "from config")
+	}
+
+	// 存在しないファイル
+	if err := loadTemplateFile(&models.MessageTemplate{File: "missing.yaml"}, dir); err == nil {
+		t.Error("loadTemplateFile() error = nil, want error")
+	}
+}
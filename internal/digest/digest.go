@@ -0,0 +1,115 @@
+// Package digest は、記事を溜めておき指定した日時にまとめて通知するダイジェストを扱う
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// Policy は、フィードごとの通知方法（即時・ダイジェスト）とダイジェストの通知日時を管理する構造体
+type Policy struct {
+	// schedules はダイジェストで通知するフィードのURLごとの通知日時
+	schedules map[string]*Schedule
+
+	// loc は通知日時を解釈するタイムゾーン
+	loc *time.Location
+}
+
+// New は、設定からダイジェストのポリシーを作成する
+// フィードの delivery / digest_schedule はカテゴリの設定より優先される
+func New(cfg *models.Config) (*Policy, error) {
+	loc := time.Local
+	if cfg.Notification != nil && cfg.Notification.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Notification.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Notification.Timezone, err)
+		}
+		loc = l
+	}
+
+	p := &Policy{
+		schedules: make(map[string]*Schedule),
+		loc:       loc,
+	}
+
+	for name, category := range cfg.Categories {
+		if category == nil {
+			continue
+		}
+		if _, _, err := resolve(category.Delivery, category.DigestSchedule, loc); err != nil {
+			return nil, fmt.Errorf("category %s: %w", name, err)
+		}
+	}
+
+	for _, fc := range cfg.Feeds {
+		delivery := fc.Delivery
+		schedule := fc.DigestSchedule
+		if category := cfg.Categories[fc.Category]; category != nil {
+			if delivery == "" {
+				delivery = category.Delivery
+			}
+			if schedule == "" {
+				schedule = category.DigestSchedule
+			}
+		}
+
+		digest, s, err := resolve(delivery, schedule, loc)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", fc.Name, err)
+		}
+		if digest {
+			p.schedules[fc.URL] = s
+		}
+	}
+
+	return p, nil
+}
+
+// resolve は、通知方法と通知日時を検証し、ダイジェストで通知するかどうかと通知日時を返す
+func resolve(delivery, schedule string, loc *time.Location) (bool, *Schedule, error) {
+	switch delivery {
+	case "", models.DeliveryImmediate:
+		return false, nil, nil
+	case models.DeliveryDigest:
+		if schedule == "" {
+			return false, nil, fmt.Errorf("digest_schedule is required for delivery: digest")
+		}
+		s, err := ParseSchedule(schedule, loc)
+		if err != nil {
+			return false, nil, err
+		}
+		return true, s, nil
+	default:
+		return false, nil, fmt.Errorf("invalid delivery: %s", delivery)
+	}
+}
+
+// IsDigest は、指定されたフィードの記事をダイジェストで通知するかどうかを返す
+func (p *Policy) IsDigest(feedURL string) bool {
+	_, ok := p.schedules[feedURL]
+	return ok
+}
+
+// Due は、ダイジェストの待ちの記事のうち、通知日時を迎えた記事を返す
+// 待ちに追加した後の最初の通知日時が now 以前の記事が対象となる。
+// 設定の変更でダイジェストの対象でなくなったフィードの記事は、すぐに通知する
+func (p *Policy) Due(queue []*models.PendingDigestArticle, now time.Time) []*models.PendingDigestArticle {
+	due := make([]*models.PendingDigestArticle, 0)
+
+	for _, pending := range queue {
+		s, ok := p.schedules[pending.FeedURL]
+		if ok && s.Next(pending.QueuedAt).After(now) {
+			continue
+		}
+		due = append(due, pending)
+	}
+
+	return due
+}
+
+// Location は、通知日時を解釈するタイムゾーンを返す
+func (p *Policy) Location() *time.Location {
+	return p.loc
+}
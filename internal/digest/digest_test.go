@@ -0,0 +1,144 @@
+package digest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestNew は、フィードとカテゴリの設定から通知方法を決定することをテストする
+func TestNew(t *testing.T) {
+	cfg := &models.Config{
+		Notification: &models.NotificationConfig{Timezone: "Asia/Tokyo"},
+		Feeds: []*models.FeedConfig{
+			{Name: "Tech", URL: "https://example.com/tech", Category: "Tech"},
+			{Name: "Blog", URL: "https://example.com/blog", Category: "Blog"},
+			{Name: "Immediate", URL: "https://example.com/immediate", Category: "Blog", Delivery: models.DeliveryImmediate},
+			{Name: "Weekly", URL: "https://example.com/weekly", Delivery: models.DeliveryDigest, DigestSchedule: "weekly mon 09:00"},
+		},
+		Categories: map[string]*models.CategoryConfig{
+			"Blog": {Delivery: models.DeliveryDigest, DigestSchedule: "daily 09:00"},
+		},
+	}
+
+	policy, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		feedURL string
+		want    bool
+	}{
+		{"https://example.com/tech", false},
+		{"https://example.com/blog", true},
+		{"https://example.com/immediate", false},
+		{"https://example.com/weekly", true},
+	}
+
+	for _, tt := range tests {
+		if got := policy.IsDigest(tt.feedURL); got != tt.want {
+			t.Errorf("IsDigest(%s) = %v, want %v", tt.feedURL, got, tt.want)
+		}
+	}
+
+	if policy.Location().String() != "Asia/Tokyo" {
+		t.Errorf("Location() = %v, want Asia/Tokyo", policy.Location())
+	}
+}
+
+// TestNewInvalid は、不正な設定をエラーにすることをテストする
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *models.Config
+	}{
+		{
+			"通知日時がない",
+			&models.Config{
+				Feeds: []*models.FeedConfig{{Name: "Feed", URL: "https://example.com/feed", Delivery: models.DeliveryDigest}},
+			},
+		},
+		{
+			"不明な通知方法",
+			&models.Config{
+				Feeds: []*models.FeedConfig{{Name: "Feed", URL: "https://example.com/feed", Delivery: "weekly"}},
+			},
+		},
+		{
+			"カテゴリの通知日時がない",
+			&models.Config{
+				Feeds: []*models.FeedConfig{{Name: "Feed", URL: "https://example.com/feed", Category: "Blog"}},
+				Categories: map[string]*models.CategoryConfig{
+					"Blog": {Delivery: models.DeliveryDigest},
+				},
+			},
+		},
+		{
+			"無効なフィードの通知日時がない",
+			&models.Config{
+				Feeds: []*models.FeedConfig{{Name: "Feed", URL: "https://example.com/feed", Delivery: models.DeliveryDigest, Enabled: false}},
+			},
+		},
+		{
+			"カテゴリの通知日時が不正",
+			&models.Config{
+				Categories: map[string]*models.CategoryConfig{
+					"Blog": {Delivery: models.DeliveryDigest, DigestSchedule: "daily 9am"},
+				},
+			},
+		},
+		{
+			"不明なタイムゾーン",
+			&models.Config{
+				Notification: &models.NotificationConfig{Timezone: "Mars/Olympus"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.cfg); err == nil {
+				t.Error("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestDue は、通知日時を迎えた記事の判定をテストする
+func TestDue(t *testing.T) {
+	cfg := &models.Config{
+		Notification: &models.NotificationConfig{Timezone: "UTC"},
+		Feeds: []*models.FeedConfig{
+			{Name: "Daily", URL: "https://example.com/daily", Delivery: models.DeliveryDigest, DigestSchedule: "daily 09:00"},
+		},
+	}
+
+	policy, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	now := time.Date(2025, 11, 13, 9, 10, 0, 0, time.UTC)
+	queue := []*models.PendingDigestArticle{
+		// 通知時刻前に追加 → 通知する
+		{ID: "1", FeedURL: "https://example.com/daily", QueuedAt: time.Date(2025, 11, 13, 8, 0, 0, 0, time.UTC)},
+		// 前日に追加 → 通知する
+		{ID: "2", FeedURL: "https://example.com/daily", QueuedAt: time.Date(2025, 11, 12, 20, 0, 0, 0, time.UTC)},
+		// 通知時刻後に追加 → 翌日まで待つ
+		{ID: "3", FeedURL: "https://example.com/daily", QueuedAt: time.Date(2025, 11, 13, 9, 5, 0, 0, time.UTC)},
+		// ダイジェストの対象でなくなったフィード → すぐに通知する
+		{ID: "4", FeedURL: "https://example.com/removed", QueuedAt: now},
+	}
+
+	due := policy.Due(queue, now)
+
+	var ids []string
+	for _, pending := range due {
+		ids = append(ids, pending.ID)
+	}
+	if len(ids) != 3 || ids[0] != "1" || ids[1] != "2" || ids[2] != "4" {
+		t.Errorf("Due() = %v, want [1 2 4]", ids)
+	}
+}
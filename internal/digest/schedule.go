@@ -0,0 +1,89 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// weekdays は、weekly の曜日に指定できる値
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule は、ダイジェストを通知する日時
+type Schedule struct {
+	// spec は設定ファイルに指定された値
+	spec string
+
+	// weekly は毎週（false の場合は毎日）
+	weekly bool
+
+	// weekday は weekly の場合の曜日
+	weekday time.Weekday
+
+	// hour, minute は通知する時刻
+	hour, minute int
+
+	// loc は時刻を解釈するタイムゾーン
+	loc *time.Location
+}
+
+// ParseSchedule は、ダイジェストの通知日時を解析する
+// 形式は "daily HH:MM" または "weekly <曜日> HH:MM"（曜日は sun〜sat）
+func ParseSchedule(spec string, loc *time.Location) (*Schedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	parts := strings.Fields(strings.ToLower(spec))
+	s := &Schedule{spec: spec, loc: loc}
+
+	var clock string
+	switch {
+	case len(parts) == 2 && parts[0] == "daily":
+		clock = parts[1]
+	case len(parts) == 3 && parts[0] == "weekly":
+		weekday, ok := weekdays[parts[1]]
+		if !ok {
+			return nil, fmt.Errorf("invalid digest_schedule %q: unknown weekday %q", spec, parts[1])
+		}
+		s.weekly = true
+		s.weekday = weekday
+		clock = parts[2]
+	default:
+		return nil, fmt.Errorf("invalid digest_schedule %q (expected \"daily HH:MM\" or \"weekly mon HH:MM\")", spec)
+	}
+
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return nil, fmt.Errorf("invalid digest_schedule %q: invalid time %q", spec, clock)
+	}
+	s.hour = t.Hour()
+	s.minute = t.Minute()
+
+	return s, nil
+}
+
+// Next は、after より後で最初の通知日時を返す
+func (s *Schedule) Next(after time.Time) time.Time {
+	local := after.In(s.loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, s.loc)
+
+	for !next.After(after) || (s.weekly && next.Weekday() != s.weekday) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, s.hour, s.minute, 0, 0, s.loc)
+	}
+
+	return next
+}
+
+// String は、設定ファイルに指定された値を返す
+func (s *Schedule) String() string {
+	return s.spec
+}
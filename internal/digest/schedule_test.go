@@ -0,0 +1,95 @@
+package digest
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseSchedule は、ダイジェストの通知日時の解析をテストする
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"毎日", "daily 09:00", false},
+		{"毎週", "weekly mon 18:30", false},
+		{"大文字", "Weekly FRI 07:05", false},
+		{"曜日が不正", "weekly monday 09:00", true},
+		{"時刻が不正", "daily 25:00", true},
+		{"時刻がない", "daily", true},
+		{"不明な形式", "hourly", true},
+		{"空", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(tt.spec, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchedule(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestScheduleNext は、次の通知日時の計算をテストする
+func TestScheduleNext(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+
+	tests := []struct {
+		name  string
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			"毎日・当日の通知時刻前",
+			"daily 09:00",
+			time.Date(2025, 11, 13, 8, 0, 0, 0, jst),
+			time.Date(2025, 11, 13, 9, 0, 0, 0, jst),
+		},
+		{
+			"毎日・当日の通知時刻後",
+			"daily 09:00",
+			time.Date(2025, 11, 13, 9, 30, 0, 0, jst),
+			time.Date(2025, 11, 14, 9, 0, 0, 0, jst),
+		},
+		{
+			"毎日・通知時刻ちょうどは翌日",
+			"daily 09:00",
+			time.Date(2025, 11, 13, 9, 0, 0, 0, jst),
+			time.Date(2025, 11, 14, 9, 0, 0, 0, jst),
+		},
+		{
+			"毎日・UTCの日時を指定",
+			"daily 09:00",
+			time.Date(2025, 11, 12, 23, 0, 0, 0, time.UTC), // JST 11/13 08:00
+			time.Date(2025, 11, 13, 9, 0, 0, 0, jst),
+		},
+		{
+			"毎週・同じ週",
+			"weekly fri 18:00",
+			time.Date(2025, 11, 13, 12, 0, 0, 0, jst), // 木曜日
+			time.Date(2025, 11, 14, 18, 0, 0, 0, jst),
+		},
+		{
+			"毎週・翌週",
+			"weekly mon 09:00",
+			time.Date(2025, 11, 17, 10, 0, 0, 0, jst), // 月曜日の通知時刻後
+			time.Date(2025, 11, 24, 9, 0, 0, 0, jst),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec, jst)
+			if err != nil {
+				t.Fatalf("ParseSchedule() error = %v", err)
+			}
+
+			if got := s.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// SplitIntoMessages は、記事を1つのメッセージにまとめられる単位に分割する
+// 1メッセージあたりの記事数は maxEmbeds（最大10）件まで、Embedの文字数の合計は6000文字までに収める
+func (n *Notifier) SplitIntoMessages(articles []*models.Article, maxEmbeds int) [][]*models.Article {
+	if maxEmbeds <= 0 {
+		maxEmbeds = 1
+	}
+	if maxEmbeds > MaxEmbedsPerMessage {
+		maxEmbeds = MaxEmbedsPerMessage
+	}
+
+	var messages [][]*models.Article
+	var current []*models.Article
+	currentLength := 0
+
+	for _, article := range articles {
+		// 送信時と同じくDiscordの制限に合わせて切り詰めた文字数で判定する
+		embed := n.createEmbed(article)
+		normalizeEmbed(&embed)
+		length := embedLength(embed)
+
+		if len(current) > 0 && (len(current) >= maxEmbeds || currentLength+length > maxEmbedsTotalLength) {
+			messages = append(messages, current)
+			current = nil
+			currentLength = 0
+		}
+
+		current = append(current, article)
+		currentLength += length
+	}
+
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
+
+// SendBatch は、複数の記事を1つのメッセージ（複数のEmbed）としてDiscordに通知する
+// 記事は SplitIntoMessages で分割した単位で渡すこと
+// 通知に成功した場合は、投稿されたメッセージのIDを各記事に記録する
+func (n *Notifier) SendBatch(ctx context.Context, articles []*models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	message := n.createBatchMessage(articles)
+
+	posted, err := n.sendWithRetry(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send %d articles: %w", len(articles), err)
+	}
+	recordPostedMessage(posted, articles...)
+
+	for _, article := range articles {
+		logger.Info("記事を通知しました",
+			"title", article.Title,
+			"feed", article.FeedName,
+			"category", article.Category,
+			"embeds", len(articles))
+	}
+
+	return nil
+}
+
+// createBatchMessage は、複数の記事から1つのDiscordメッセージを作成する
+// メンションとテンプレートの content は重複を除いて1回だけ付与する
+func (n *Notifier) createBatchMessage(articles []*models.Article) *WebhookMessage {
+	message := &WebhookMessage{
+		Embeds: make([]Embed, 0, len(articles)),
+	}
+
+	var mentions, contents []string
+	seen := make(map[string]bool)
+	for _, article := range articles {
+		message.Embeds = append(message.Embeds, n.createEmbed(article))
+
+		for _, mention := range article.Mentions {
+			if !seen[mention] {
+				seen[mention] = true
+				mentions = append(mentions, mention)
+			}
+		}
+
+		if text := n.renderContent(article); text != "" && !seen[text] {
+			seen[text] = true
+			contents = append(contents, text)
+		}
+	}
+	message.Content = strings.TrimSpace(strings.Join(mentions, " ") + "\n" + strings.Join(contents, "\n"))
+	message.AllowedMentions = allowedMentionsFor(mentions)
+
+	return message
+}
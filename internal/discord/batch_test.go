@@ -0,0 +1,161 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// newTestArticles は、テスト用の記事を作成する
+func newTestArticles(count int, description string) []*models.Article {
+	articles := make([]*models.Article, 0, count)
+	for i := 0; i < count; i++ {
+		articles = append(articles, &models.Article{
+			ID:          fmt.Sprintf("article-%d", i),
+			Title:       fmt.Sprintf("Article %d", i),
+			URL:         fmt.Sprintf("https://example.com/article-%d", i),
+			Description: description,
+			PublishedAt: time.Now(),
+			FeedName:    "Test Feed",
+			Category:    "Tech",
+		})
+	}
+	return articles
+}
+
+// TestSplitIntoMessages は、記事のメッセージ単位への分割をテストする
+func TestSplitIntoMessages(t *testing.T) {
+	notifier := NewNotifier("https://test.com", 0)
+
+	tests := []struct {
+		name      string
+		articles  []*models.Article
+		maxEmbeds int
+		want      []int
+	}{
+		{"まとめない", newTestArticles(3, "short"), 1, []int{1, 1, 1}},
+		{"件数で分割", newTestArticles(7, "short"), 3, []int{3, 3, 1}},
+		{"上限は10件", newTestArticles(12, "short"), 20, []int{10, 2}},
+		// 説明文は300文字に短縮されるため、1件あたり約370文字（日本語のフィールド名を含む）
+		{"6000文字で分割", newTestArticles(20, strings.Repeat("あ", 1000)), 10, []int{10, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := notifier.SplitIntoMessages(tt.articles, tt.maxEmbeds)
+
+			got := make([]int, 0, len(messages))
+			total := 0
+			for _, m := range messages {
+				got = append(got, len(m))
+
+				length := 0
+				for _, article := range m {
+					length += embedLength(notifier.createEmbed(article))
+				}
+				if length > maxEmbedsTotalLength {
+					t.Errorf("message length = %d, want <= %d", length, maxEmbedsTotalLength)
+				}
+				total += len(m)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("message sizes = %v, want %v", got, tt.want)
+			}
+			if total != len(tt.articles) {
+				t.Errorf("total articles = %d, want %d", total, len(tt.articles))
+			}
+		})
+	}
+}
+
+// TestSplitIntoMessagesTotalLength は、文字数の合計が6000文字を超えないよう分割することをテストする
+func TestSplitIntoMessagesTotalLength(t *testing.T) {
+	// 説明文を短縮しないテンプレート（1件あたり約2000文字）
+	templates, err := NewTemplateSet(&models.Config{
+		Feeds:     []*models.FeedConfig{{Name: "Test Feed", URL: "https://example.com/feed", Template: "full"}},
+		Templates: map[string]*models.MessageTemplate{"full": {Description: "{{.Description}}"}},
+	})
+	if err != nil {
+		t.Fatalf("NewTemplateSet() error = %v", err)
+	}
+
+	notifier := NewNotifier("https://test.com", 0)
+	notifier.SetTemplates(templates)
+
+	articles := newTestArticles(5, strings.Repeat("d", 2000))
+	for _, article := range articles {
+		article.FeedURL = "https://example.com/feed"
+	}
+
+	messages := notifier.SplitIntoMessages(articles, 10)
+	if len(messages) != 3 {
+		t.Fatalf("messages = %d, want 3", len(messages))
+	}
+	if len(messages[0]) != 2 || len(messages[1]) != 2 || len(messages[2]) != 1 {
+		t.Errorf("message sizes = %d, %d, %d, want 2, 2, 1", len(messages[0]), len(messages[1]), len(messages[2]))
+	}
+}
+
+// TestCreateBatchMessage は、複数記事のメッセージ作成をテストする
+func TestCreateBatchMessage(t *testing.T) {
+	notifier := NewNotifier("https://test.com", 0)
+
+	articles := newTestArticles(3, "short")
+	articles[0].Mentions = []string{"<@&100>"}
+	articles[1].Mentions = []string{"<@&100>", "<@200>"}
+
+	message := notifier.createBatchMessage(articles)
+
+	if len(message.Embeds) != 3 {
+		t.Fatalf("Embeds length = %d, want 3", len(message.Embeds))
+	}
+	if message.Embeds[2].Title != "Article 2" {
+		t.Errorf("Embeds[2].Title = %v, want %v", message.Embeds[2].Title, "Article 2")
+	}
+
+	// メンションは重複を除いて1回だけ
+	if message.Content != "<@&100> <@200>" {
+		t.Errorf("Content = %q, want %q", message.Content, "<@&100> <@200>")
+	}
+}
+
+// TestSendBatch は、複数記事を1回のリクエストで送信することをテストする
+func TestSendBatch(t *testing.T) {
+	requestCount := 0
+	embedCount := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+
+		var message WebhookMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		embedCount += len(message.Embeds)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	if err := notifier.SendBatch(context.Background(), newTestArticles(4, "short")); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	if requestCount != 1 {
+		t.Errorf("Request count = %d, want 1", requestCount)
+	}
+	if embedCount != 4 {
+		t.Errorf("Embed count = %d, want 4", embedCount)
+	}
+}
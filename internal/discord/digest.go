@@ -0,0 +1,126 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+const (
+	// maxDigestTitleLength はダイジェストに記載する記事タイトルの上限
+	maxDigestTitleLength = 200
+
+	// digestColor はダイジェストのEmbedの色（Discord Blurple）
+	digestColor = 5793522
+)
+
+// SplitDigest は、ダイジェストの記事を1つのメッセージに収まる単位に分割する
+// 記事はフィードごとにまとめ、Embedの説明文が4096文字に収まるように分割する
+func (n *Notifier) SplitDigest(articles []*models.Article) [][]*models.Article {
+	var messages [][]*models.Article
+	var current []*models.Article
+
+	for _, article := range groupByFeed(articles) {
+		candidate := append(current[:len(current):len(current)], article)
+		if len(current) > 0 && utf8.RuneCountInString(digestDescription(candidate)) > maxDescriptionLength {
+			messages = append(messages, current)
+			candidate = []*models.Article{article}
+		}
+		current = candidate
+	}
+
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
+
+// SendDigest は、複数の記事を1つのダイジェストメッセージとしてDiscordに通知する
+// 記事は SplitDigest で分割した単位で渡すこと
+// 通知に成功した場合は、投稿されたメッセージのIDを各記事に記録する
+func (n *Notifier) SendDigest(ctx context.Context, title string, articles []*models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	message := createDigestMessage(title, articles)
+
+	posted, err := n.sendWithRetry(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to send digest (%d articles): %w", len(articles), err)
+	}
+	recordPostedMessage(posted, articles...)
+
+	logger.Info("ダイジェストを通知しました",
+		"title", title,
+		"articles", len(articles))
+
+	return nil
+}
+
+// createDigestMessage は、ダイジェストのDiscordメッセージを作成する
+func createDigestMessage(title string, articles []*models.Article) *WebhookMessage {
+	return &WebhookMessage{
+		Embeds: []Embed{
+			{
+				Title:       title,
+				Description: digestDescription(articles),
+				Color:       digestColor,
+				Timestamp:   time.Now().Format(time.RFC3339),
+				Footer: &EmbedFooter{
+					Text: fmt.Sprintf("RSS Discord Notifier ・ %d件", len(articles)),
+				},
+			},
+		},
+	}
+}
+
+// digestDescription は、記事をフィードごとにまとめ、タイトルをリンクにした一覧を作成する
+// 記事はフィードごとに連続して並んでいること（groupByFeed）
+func digestDescription(articles []*models.Article) string {
+	var b strings.Builder
+	feedName := ""
+
+	for i, article := range articles {
+		if i == 0 || article.FeedName != feedName {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			feedName = article.FeedName
+			fmt.Fprintf(&b, "**%s**\n", htmlconv.EscapeMarkdown(feedName))
+		}
+
+		title := escapeFeedText(truncateRunes(article.Title, maxDigestTitleLength))
+		fmt.Fprintf(&b, "• [%s](%s)\n", title, htmlconv.EscapeLinkURL(article.URL))
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// groupByFeed は、記事をフィードごとに連続するように並べ替える
+// フィードの順序は最初に現れた順、フィード内の記事の順序は維持する
+func groupByFeed(articles []*models.Article) []*models.Article {
+	var feeds []string
+	byFeed := make(map[string][]*models.Article)
+
+	for _, article := range articles {
+		if _, ok := byFeed[article.FeedName]; !ok {
+			feeds = append(feeds, article.FeedName)
+		}
+		byFeed[article.FeedName] = append(byFeed[article.FeedName], article)
+	}
+
+	grouped := make([]*models.Article, 0, len(articles))
+	for _, feed := range feeds {
+		grouped = append(grouped, byFeed[feed]...)
+	}
+
+	return grouped
+}
@@ -0,0 +1,97 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestDigestDescription は、ダイジェストの一覧がフィードごとにまとめられることをテストする
+func TestDigestDescription(t *testing.T) {
+	articles := groupByFeed([]*models.Article{
+		{Title: "Go 1.22", URL: "https://go.dev/1", FeedName: "Go Blog"},
+		{Title: "GitHub news", URL: "https://github.blog/1", FeedName: "GitHub Blog"},
+		{Title: "Go *fast*", URL: "https://go.dev/2", FeedName: "Go Blog"},
+		{Title: "[Go] release", URL: "https://go.dev/wiki/Go (2)", FeedName: "Go Blog"},
+	})
+
+	want := "**Go Blog**\n" +
+		"• [Go 1.22](https://go.dev/1)\n" +
+		"• [Go \\*fast\\*](https://go.dev/2)\n" +
+		"• [\\[Go\\] release](https://go.dev/wiki/Go%20%282%29)\n" +
+		"\n" +
+		"**GitHub Blog**\n" +
+		"• [GitHub news](https://github.blog/1)"
+
+	if got := digestDescription(articles); got != want {
+		t.Errorf("digestDescription() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestSplitDigest は、説明文が4096文字に収まるように分割することをテストする
+func TestSplitDigest(t *testing.T) {
+	notifier := NewNotifier("https://test.com", 0)
+
+	articles := make([]*models.Article, 0, 100)
+	for i := 0; i < 100; i++ {
+		articles = append(articles, &models.Article{
+			Title:    strings.Repeat("あ", 100),
+			URL:      fmt.Sprintf("https://example.com/article-%d", i),
+			FeedName: fmt.Sprintf("Feed %d", i%3),
+		})
+	}
+
+	messages := notifier.SplitDigest(articles)
+	if len(messages) < 2 {
+		t.Fatalf("messages = %d, want >= 2", len(messages))
+	}
+
+	total := 0
+	for _, m := range messages {
+		if length := utf8.RuneCountInString(digestDescription(m)); length > maxDescriptionLength {
+			t.Errorf("description length = %d, want <= %d", length, maxDescriptionLength)
+		}
+		total += len(m)
+	}
+	if total != len(articles) {
+		t.Errorf("total articles = %d, want %d", total, len(articles))
+	}
+}
+
+// TestSendDigest は、ダイジェストを1つのEmbedで送信することをテストする
+func TestSendDigest(t *testing.T) {
+	var received WebhookMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	articles := newTestArticles(3, "short")
+	if err := notifier.SendDigest(context.Background(), "ダイジェスト", articles); err != nil {
+		t.Fatalf("SendDigest() error = %v", err)
+	}
+
+	if len(received.Embeds) != 1 {
+		t.Fatalf("Embeds length = %d, want 1", len(received.Embeds))
+	}
+	if received.Embeds[0].Title != "ダイジェスト" {
+		t.Errorf("Title = %v, want ダイジェスト", received.Embeds[0].Title)
+	}
+	if strings.Count(received.Embeds[0].Description, "• [") != 3 {
+		t.Errorf("Description = %q, want 3 articles", received.Embeds[0].Description)
+	}
+}
@@ -0,0 +1,234 @@
+package discord
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Discordのメッセージの制限（文字数はUnicodeのコードポイント単位）
+// https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	// MaxEmbedsPerMessage は1つのメッセージに含められるEmbedの最大数
+	MaxEmbedsPerMessage = 10
+
+	// maxContentLength はメッセージ本文の上限
+	maxContentLength = 2000
+
+	// maxTitleLength はEmbedのタイトルの上限
+	maxTitleLength = 256
+
+	// maxDescriptionLength はEmbedの説明文の上限
+	maxDescriptionLength = 4096
+
+	// maxFields はEmbedのフィールド数の上限
+	maxFields = 25
+
+	// maxFieldNameLength はフィールド名の上限
+	maxFieldNameLength = 256
+
+	// maxFieldValueLength はフィールドの値の上限
+	maxFieldValueLength = 1024
+
+	// maxFooterLength はフッターのテキストの上限
+	maxFooterLength = 2048
+
+	// maxAuthorLength は著者名の上限
+	maxAuthorLength = 256
+
+	// maxEmbedsTotalLength は1つのメッセージに含まれるEmbedの文字数の合計の上限
+	maxEmbedsTotalLength = 6000
+)
+
+// emptyFieldValue は、空のフィールド名・値の代わりに使用する文字（Discordは空のフィールドを受け付けない）
+const emptyFieldValue = "\u200b"
+
+// normalizeMessage は、メッセージをDiscordの制限に収まるように切り詰める
+// 各項目の上限を超えた部分を切り詰め、Embedの合計が6000文字を超える場合は
+// 説明文・フッター・著者名・フィールド・タイトルの順に後ろのEmbedから削る
+// 変更した場合は true を返す
+func normalizeMessage(message *WebhookMessage) bool {
+	changed := false
+
+	if content := truncateRunes(message.Content, maxContentLength); content != message.Content {
+		message.Content = content
+		changed = true
+	}
+
+	if len(message.Embeds) > MaxEmbedsPerMessage {
+		message.Embeds = message.Embeds[:MaxEmbedsPerMessage]
+		changed = true
+	}
+
+	for i := range message.Embeds {
+		if normalizeEmbed(&message.Embeds[i]) {
+			changed = true
+		}
+	}
+
+	if shrinkEmbeds(message.Embeds, maxEmbedsTotalLength) {
+		changed = true
+	}
+
+	return changed
+}
+
+// normalizeEmbed は、Embedの各項目をDiscordの上限に収まるように切り詰める
+// 変更した場合は true を返す
+func normalizeEmbed(embed *Embed) bool {
+	changed := false
+	truncate := func(s *string, maxLength int) {
+		if t := truncateRunes(*s, maxLength); t != *s {
+			*s = t
+			changed = true
+		}
+	}
+
+	truncate(&embed.Title, maxTitleLength)
+	truncate(&embed.Description, maxDescriptionLength)
+
+	if len(embed.Fields) > maxFields {
+		embed.Fields = embed.Fields[:maxFields]
+		changed = true
+	}
+	for i := range embed.Fields {
+		field := &embed.Fields[i]
+		truncate(&field.Name, maxFieldNameLength)
+		truncate(&field.Value, maxFieldValueLength)
+		if field.Name == "" {
+			field.Name = emptyFieldValue
+			changed = true
+		}
+		if field.Value == "" {
+			field.Value = emptyFieldValue
+			changed = true
+		}
+	}
+
+	if embed.Footer != nil {
+		truncate(&embed.Footer.Text, maxFooterLength)
+	}
+	if embed.Author != nil {
+		truncate(&embed.Author.Name, maxAuthorLength)
+	}
+
+	return changed
+}
+
+// shrinkEmbeds は、Embedの文字数の合計が maxLength 以下になるように削る
+// 変更した場合は true を返す
+func shrinkEmbeds(embeds []Embed, maxLength int) bool {
+	excess := -maxLength
+	for _, embed := range embeds {
+		excess += embedLength(embed)
+	}
+	if excess <= 0 {
+		return false
+	}
+
+	// shrink は、文字列を excess だけ（最大で空文字列まで）短くする
+	shrink := func(s *string) {
+		if excess <= 0 || *s == "" {
+			return
+		}
+		length := utf8.RuneCountInString(*s)
+		if length <= excess {
+			*s = ""
+			excess -= length
+			return
+		}
+		*s = truncateRunes(*s, length-excess)
+		excess -= length - utf8.RuneCountInString(*s)
+	}
+
+	// 説明文
+	for i := len(embeds) - 1; i >= 0 && excess > 0; i-- {
+		shrink(&embeds[i].Description)
+	}
+
+	// フッター・著者名
+	for i := len(embeds) - 1; i >= 0 && excess > 0; i-- {
+		if footer := embeds[i].Footer; footer != nil {
+			shrink(&footer.Text)
+			if footer.Text == "" {
+				embeds[i].Footer = nil
+			}
+		}
+		if author := embeds[i].Author; author != nil {
+			shrink(&author.Name)
+			if author.Name == "" {
+				embeds[i].Author = nil
+			}
+		}
+	}
+
+	// フィールド（後ろから削除）
+	for i := len(embeds) - 1; i >= 0 && excess > 0; i-- {
+		for len(embeds[i].Fields) > 0 && excess > 0 {
+			last := embeds[i].Fields[len(embeds[i].Fields)-1]
+			excess -= utf8.RuneCountInString(last.Name) + utf8.RuneCountInString(last.Value)
+			embeds[i].Fields = embeds[i].Fields[:len(embeds[i].Fields)-1]
+		}
+	}
+
+	// タイトル
+	for i := len(embeds) - 1; i >= 0 && excess > 0; i-- {
+		shrink(&embeds[i].Title)
+	}
+
+	return true
+}
+
+// embedLength は、Discordの6000文字制限の対象となるEmbedの文字数を返す
+// 対象は title, description, fields の name と value, footer の text, author の name
+func embedLength(embed Embed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		length += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		length += utf8.RuneCountInString(embed.Author.Name)
+	}
+
+	return length
+}
+
+// truncateRunes は、文字列を最大 maxLength 文字（rune単位）に切り詰める
+// 超えた場合は末尾を ... にし、結合文字や絵文字のシーケンス（ZWJ・異体字セレクタ）の途中では切らない
+func truncateRunes(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	if maxLength <= 0 {
+		return ""
+	}
+
+	runes := []rune(s)
+	suffix := "..."
+	cut := maxLength - len(suffix)
+	if cut <= 0 {
+		suffix = ""
+		cut = maxLength
+	}
+
+	// 切り捨てる最初の文字が直前の文字に結合する場合は、結合先の文字ごと切り捨てる
+	for cut > 0 && (isGraphemeExtend(runes[cut]) || runes[cut-1] == zeroWidthJoiner) {
+		cut--
+	}
+
+	return string(runes[:cut]) + suffix
+}
+
+// zeroWidthJoiner は、絵文字を結合するゼロ幅接合子
+const zeroWidthJoiner = '\u200d'
+
+// isGraphemeExtend は、直前の文字と結合して1文字として表示される文字かを返す
+func isGraphemeExtend(r rune) bool {
+	return r == zeroWidthJoiner ||
+		unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) ||
+		unicode.Is(unicode.Variation_Selector, r) ||
+		(r >= 0x1F3FB && r <= 0x1F3FF) // 絵文字の肌の色
+}
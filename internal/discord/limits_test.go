@@ -0,0 +1,174 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestTruncateRunes は、文字単位の切り詰めをテストする
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name      string
+		s         string
+		maxLength int
+		want      string
+	}{
+		{"上限以内", "こんにちは", 5, "こんにちは"},
+		{"日本語", "こんにちは世界", 6, "こんに..."},
+		{"上限が小さい", "こんにちは", 2, "こん"},
+		{"上限が0", "こんにちは", 0, ""},
+		{"結合文字の途中で切らない", "abcdéfgh", 8, "abcd..."},
+		{"ZWJシーケンスの途中で切らない", "ab👩‍💻xyz", 6, "ab..."},
+		{"異体字セレクタの途中で切らない", "abc❤️xyz", 7, "abc..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateRunes(tt.s, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.maxLength, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateRunes(%q, %d) returned invalid UTF-8", tt.s, tt.maxLength)
+			}
+			if utf8.RuneCountInString(got) > max(tt.maxLength, 0) {
+				t.Errorf("length = %d, want <= %d", utf8.RuneCountInString(got), tt.maxLength)
+			}
+		})
+	}
+}
+
+// TestNormalizeMessage は、各項目の上限に合わせた切り詰めをテストする
+func TestNormalizeMessage(t *testing.T) {
+	fields := make([]EmbedField, 30)
+	for i := range fields {
+		fields[i] = EmbedField{Name: strings.Repeat("名", 300), Value: strings.Repeat("値", 2000)}
+	}
+	fields[0].Value = ""
+
+	message := &WebhookMessage{
+		Content: strings.Repeat("あ", 2500),
+		Embeds: []Embed{
+			{
+				Title:       strings.Repeat("題", 300),
+				Description: strings.Repeat("説", 5000),
+				Fields:      fields,
+				Footer:      &EmbedFooter{Text: strings.Repeat("足", 3000)},
+				Author:      &EmbedAuthor{Name: strings.Repeat("著", 300)},
+			},
+		},
+	}
+
+	if !normalizeMessage(message) {
+		t.Fatal("normalizeMessage() = false, want true")
+	}
+
+	embed := message.Embeds[0]
+	checks := []struct {
+		name   string
+		value  string
+		maxLen int
+	}{
+		{"content", message.Content, maxContentLength},
+		{"title", embed.Title, maxTitleLength},
+		{"description", embed.Description, maxDescriptionLength},
+		{"field name", embed.Fields[1].Name, maxFieldNameLength},
+		{"field value", embed.Fields[1].Value, maxFieldValueLength},
+	}
+	for _, c := range checks {
+		if length := utf8.RuneCountInString(c.value); length > c.maxLen {
+			t.Errorf("%s length = %d, want <= %d", c.name, length, c.maxLen)
+		}
+	}
+
+	if len(embed.Fields) > maxFields {
+		t.Errorf("Fields length = %d, want <= %d", len(embed.Fields), maxFields)
+	}
+	if embed.Fields[0].Value == "" {
+		t.Error("empty field value should be replaced")
+	}
+	if total := embedLength(embed); total > maxEmbedsTotalLength {
+		t.Errorf("total length = %d, want <= %d", total, maxEmbedsTotalLength)
+	}
+}
+
+// TestNormalizeMessageTotalLength は、複数のEmbedの合計を6000文字に収めることをテストする
+func TestNormalizeMessageTotalLength(t *testing.T) {
+	embeds := make([]Embed, 12)
+	for i := range embeds {
+		embeds[i] = Embed{
+			Title:       "Title",
+			Description: strings.Repeat("d", 1000),
+			Fields:      []EmbedField{{Name: "Feed", Value: "Test"}},
+		}
+	}
+	message := &WebhookMessage{Embeds: embeds}
+
+	normalizeMessage(message)
+
+	if len(message.Embeds) != MaxEmbedsPerMessage {
+		t.Fatalf("Embeds length = %d, want %d", len(message.Embeds), MaxEmbedsPerMessage)
+	}
+
+	total := 0
+	for _, embed := range message.Embeds {
+		total += embedLength(embed)
+	}
+	if total > maxEmbedsTotalLength {
+		t.Errorf("total length = %d, want <= %d", total, maxEmbedsTotalLength)
+	}
+
+	// 先頭のEmbedの説明文は残す（後ろのEmbedから削る）
+	if message.Embeds[0].Description != strings.Repeat("d", 1000) {
+		t.Error("first embed description should be kept")
+	}
+	if message.Embeds[0].Title != "Title" || len(message.Embeds[9].Fields) != 1 {
+		t.Error("titles and fields should be kept when descriptions are enough")
+	}
+}
+
+// TestSendArticleLongTitle は、上限を超えるタイトルを切り詰めて送信することをテストする
+func TestSendArticleLongTitle(t *testing.T) {
+	var received WebhookMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		Title:       strings.Repeat("長いタイトル", 100),
+		URL:         "https://example.com/article",
+		Description: strings.Repeat("日本語の説明文", 100),
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+		Category:    "Tech",
+	}
+
+	if err := notifier.SendArticle(context.Background(), article); err != nil {
+		t.Fatalf("SendArticle() error = %v", err)
+	}
+
+	embed := received.Embeds[0]
+	if length := utf8.RuneCountInString(embed.Title); length > maxTitleLength {
+		t.Errorf("Title length = %d, want <= %d", length, maxTitleLength)
+	}
+	// 説明文は文字単位で300文字に短縮される（マルチバイト文字の途中で切らない）
+	if !utf8.ValidString(embed.Description) || utf8.RuneCountInString(embed.Description) != 300 {
+		t.Errorf("Description = %q (%d runes), want valid 300 runes", embed.Description, utf8.RuneCountInString(embed.Description))
+	}
+}
@@ -0,0 +1,70 @@
+package discord
+
+import (
+	"regexp"
+
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+var (
+	// roleMentionPattern はロールのメンション（<@&ROLE_ID>）
+	roleMentionPattern = regexp.MustCompile(`^<@&(\d+)>$`)
+
+	// userMentionPattern はユーザーのメンション（<@USER_ID> / <@!USER_ID>）
+	userMentionPattern = regexp.MustCompile(`^<@!?(\d+)>$`)
+)
+
+// allowedMentionsFor は、設定されたメンションのみを許可する allowed_mentions を作成する
+// メンションがない場合は、すべてのメンションを通知しない
+func allowedMentionsFor(mentions []string) *AllowedMentions {
+	allowed := &AllowedMentions{Parse: []string{}}
+
+	for _, mention := range mentions {
+		switch {
+		case mention == "@everyone" || mention == "@here":
+			if len(allowed.Parse) == 0 {
+				allowed.Parse = append(allowed.Parse, "everyone")
+			}
+		case roleMentionPattern.MatchString(mention):
+			allowed.Roles = append(allowed.Roles, roleMentionPattern.FindStringSubmatch(mention)[1])
+		case userMentionPattern.MatchString(mention):
+			allowed.Users = append(allowed.Users, userMentionPattern.FindStringSubmatch(mention)[1])
+		}
+	}
+
+	return allowed
+}
+
+// escapeFeedText は、フィードから取得したテキストのMarkdown記号とメンションをエスケープする
+func escapeFeedText(s string) string {
+	return htmlconv.EscapeMentions(htmlconv.EscapeMarkdown(s))
+}
+
+// escapeDescription は、記事の説明文をエスケープする
+// Markdownに変換済みの説明文（description_format: markdown）はメンションのみを無効化する
+func escapeDescription(description string, isMarkdown bool) string {
+	if isMarkdown {
+		return htmlconv.EscapeMentions(description)
+	}
+	return escapeFeedText(description)
+}
+
+// templateArticle は、テンプレートに渡す記事を作成する
+// フィードから取得したテキストのメンションを無効化する（Markdown記号はテンプレートの escapeMarkdown でエスケープする）
+func templateArticle(article *models.Article) *models.Article {
+	safe := *article
+	safe.Title = htmlconv.EscapeMentions(article.Title)
+	safe.Description = htmlconv.EscapeMentions(article.Description)
+	safe.Content = htmlconv.EscapeMentions(article.Content)
+	safe.Author = htmlconv.EscapeMentions(article.Author)
+
+	if article.Tags != nil {
+		safe.Tags = make([]string, len(article.Tags))
+		for i, tag := range article.Tags {
+			safe.Tags[i] = htmlconv.EscapeMentions(tag)
+		}
+	}
+
+	return &safe
+}
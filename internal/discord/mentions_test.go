@@ -0,0 +1,193 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestAllowedMentionsFor は、設定されたメンションからの allowed_mentions の作成をテストする
+func TestAllowedMentionsFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		mentions []string
+		want     string
+	}{
+		{
+			name:     "メンションなし",
+			mentions: nil,
+			want:     `{"parse":[]}`,
+		},
+		{
+			name:     "ロールとユーザー",
+			mentions: []string{"<@&100>", "<@200>", "<@!300>"},
+			want:     `{"parse":[],"roles":["100"],"users":["200","300"]}`,
+		},
+		{
+			name:     "@here と @everyone",
+			mentions: []string{"@here", "@everyone"},
+			want:     `{"parse":["everyone"]}`,
+		},
+		{
+			name:     "解釈できないメンション",
+			mentions: []string{"@someone", "<#400>"},
+			want:     `{"parse":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(allowedMentionsFor(tt.mentions))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("allowedMentionsFor() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+// TestDefaultEmbedEscapesFeedText は、フィードから取得したテキストのエスケープをテストする
+func TestDefaultEmbedEscapesFeedText(t *testing.T) {
+	tests := []struct {
+		name            string
+		article         *models.Article
+		wantTitle       string
+		wantDescription string
+	}{
+		{
+			name: "テキストの説明文",
+			article: &models.Article{
+				Title:       "@everyone **Breaking**",
+				Description: "[click](https://evil.example) <@&123>",
+			},
+			wantTitle:       "@\u200beveryone \\*\\*Breaking\\*\\*",
+			wantDescription: "\\[click\\](https://evil.example) <\u200b@&123\\>",
+		},
+		{
+			name: "Markdownに変換済みの説明文",
+			article: &models.Article{
+				Title:                 "Release",
+				Description:           "**bold** [docs](https://example.com) @here",
+				DescriptionIsMarkdown: true,
+			},
+			wantTitle:       "Release",
+			wantDescription: "**bold** [docs](https://example.com) @\u200bhere",
+		},
+		{
+			name: "長いMarkdownの説明文はリンクの途中で切らない",
+			article: &models.Article{
+				Title:                 "Release",
+				Description:           strings.Repeat("word ", 58) + "[release notes](https://example.com/releases/v2) and more",
+				DescriptionIsMarkdown: true,
+			},
+			wantTitle:       "Release",
+			wantDescription: strings.TrimSpace(strings.Repeat("word ", 58)) + "...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.article.PublishedAt = time.Now()
+			embed := defaultEmbed(tt.article)
+			if embed.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", embed.Title, tt.wantTitle)
+			}
+			if embed.Description != tt.wantDescription {
+				t.Errorf("Description = %q, want %q", embed.Description, tt.wantDescription)
+			}
+		})
+	}
+}
+
+// TestCreateMessageTemplateEscapesMentions は、テンプレートに渡すテキストのメンションの無効化をテストする
+func TestCreateMessageTemplateEscapesMentions(t *testing.T) {
+	set, err := NewTemplateSet(newTemplateConfig(map[string]*models.MessageTemplate{
+		"compact": {
+			Content: "新着: {{.Title}}",
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewTemplateSet() error = %v", err)
+	}
+
+	notifier := NewNotifier("https://test.com", 0)
+	notifier.SetTemplates(set)
+
+	article := &models.Article{
+		Title:       "@everyone free nitro",
+		URL:         "https://example.com/article",
+		PublishedAt: time.Now(),
+		FeedURL:     "https://example.com/feed",
+	}
+
+	message := notifier.createMessage(article)
+	if message.Content != "新着: @\u200beveryone free nitro" {
+		t.Errorf("Content = %q", message.Content)
+	}
+	if !reflect.DeepEqual(message.AllowedMentions, &AllowedMentions{Parse: []string{}}) {
+		t.Errorf("AllowedMentions = %+v", message.AllowedMentions)
+	}
+	// 元の記事は変更しない
+	if article.Title != "@everyone free nitro" {
+		t.Errorf("article.Title = %q", article.Title)
+	}
+}
+
+// TestSendAllowedMentions は、すべての送信で allowed_mentions が指定されることをテストする
+func TestSendAllowedMentions(t *testing.T) {
+	var bodies []map[string]json.RawMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		Title:       "Test Article",
+		URL:         "https://example.com/article",
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+		Mentions:    []string{"<@&100>"},
+	}
+
+	ctx := context.Background()
+	if err := notifier.SendArticle(ctx, article); err != nil {
+		t.Fatalf("SendArticle() error = %v", err)
+	}
+	if err := notifier.SendUpdate(ctx, article); err != nil {
+		t.Fatalf("SendUpdate() error = %v", err)
+	}
+	if err := notifier.SendDigest(ctx, "Digest", []*models.Article{article}); err != nil {
+		t.Fatalf("SendDigest() error = %v", err)
+	}
+
+	want := []string{
+		`{"parse":[],"roles":["100"]}`,
+		`{"parse":[]}`,
+		`{"parse":[]}`,
+	}
+	if len(bodies) != len(want) {
+		t.Fatalf("requests = %d, want %d", len(bodies), len(want))
+	}
+	for i, body := range bodies {
+		if got := strings.TrimSpace(string(body["allowed_mentions"])); got != want[i] {
+			t.Errorf("request %d: allowed_mentions = %s, want %s", i, got, want[i])
+		}
+	}
+}
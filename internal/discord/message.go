@@ -2,8 +2,34 @@ package discord
 
 // WebhookMessage は、Discord Webhookに送信するメッセージ
 type WebhookMessage struct {
-	Content string  `json:"content,omitempty"`
-	Embeds  []Embed `json:"embeds,omitempty"`
+	Content         string           `json:"content,omitempty"`
+	Embeds          []Embed          `json:"embeds,omitempty"`
+	AllowedMentions *AllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// AllowedMentions は、メッセージで通知を許可するメンション
+// Parse が空の場合、Roles / Users に指定したもの以外のメンションは通知されない
+type AllowedMentions struct {
+	// Parse は本文から解釈するメンションの種類（everyone: @everyone と @here）
+	Parse []string `json:"parse"`
+
+	// Roles は通知を許可するロールのID
+	Roles []string `json:"roles,omitempty"`
+
+	// Users は通知を許可するユーザーのID
+	Users []string `json:"users,omitempty"`
+}
+
+// PostedMessage は、Webhookで投稿したメッセージ（?wait=true のレスポンス）のうち使用する項目
+type PostedMessage struct {
+	// ID はメッセージID
+	ID string `json:"id"`
+
+	// ChannelID は投稿先のチャンネルID
+	ChannelID string `json:"channel_id"`
+
+	// WebhookID は投稿したWebhookのID
+	WebhookID string `json:"webhook_id"`
 }
 
 // Embed は、Discordの埋め込みメッセージ
@@ -4,11 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
 	"github.com/ken344/rss-discord-notifier/internal/logger"
 	"github.com/ken344/rss-discord-notifier/pkg/models"
 )
@@ -21,7 +25,7 @@ type Notifier struct {
 	// client はHTTPクライアント
 	client *http.Client
 
-	// rateLimit は通知間隔（レート制限対策）
+	// rateLimit はレート制限の情報がない場合の通知間隔（レート制限対策）
 	rateLimit time.Duration
 
 	// maxRetries は最大リトライ回数
@@ -29,6 +33,15 @@ type Notifier struct {
 
 	// retryDelay はリトライ間隔
 	retryDelay time.Duration
+
+	// limiter はDiscordのレート制限の状態（Webhookごとのバケットとグローバル制限）
+	limiter *RateLimiter
+
+	// maxRateLimitWait はレート制限で待機する時間の上限
+	maxRateLimitWait time.Duration
+
+	// templates はフィードごとの通知メッセージのテンプレート（nil の場合はデフォルトの表示）
+	templates *TemplateSet
 }
 
 // NewNotifier は、新しいDiscord通知器を作成する
@@ -38,22 +51,26 @@ func NewNotifier(webhookURL string, rateLimit time.Duration) *Notifier {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		rateLimit:  rateLimit,
-		maxRetries: 3,
-		retryDelay: 5 * time.Second,
+		rateLimit:        rateLimit,
+		maxRetries:       3,
+		retryDelay:       5 * time.Second,
+		limiter:          NewRateLimiter(),
+		maxRateLimitWait: defaultMaxRateLimitWait,
 	}
 }
 
 // SendArticle は、単一の記事をDiscordに通知する
+// 通知に成功した場合は、投稿されたメッセージのIDを記事（MessageID / ChannelID / WebhookID）に記録する
 func (n *Notifier) SendArticle(ctx context.Context, article *models.Article) error {
 	// Embedメッセージを作成
 	message := n.createMessage(article)
 
 	// 送信（リトライ付き）
-	err := n.sendWithRetry(ctx, message)
+	posted, err := n.sendWithRetry(ctx, message)
 	if err != nil {
 		return fmt.Errorf("failed to send article %s: %w", article.Title, err)
 	}
+	recordPostedMessage(posted, article)
 
 	logger.Info("記事を通知しました",
 		"title", article.Title,
@@ -95,10 +112,8 @@ func (n *Notifier) SendArticles(ctx context.Context, articles []*models.Article)
 
 		// 最後の記事以外は、レート制限対策で待機
 		if i < len(articles)-1 {
-			select {
-			case <-time.After(n.rateLimit):
-			case <-ctx.Done():
-				return ctx.Err()
+			if err := n.WaitInterval(ctx); err != nil {
+				return err
 			}
 		}
 	}
@@ -116,16 +131,83 @@ func (n *Notifier) SendArticles(ctx context.Context, articles []*models.Article)
 }
 
 // createMessage は、記事からDiscordメッセージを作成する
+// メッセージ本文はメンションとテンプレートの content で、通知するメンションは設定されたもののみ
 func (n *Notifier) createMessage(article *models.Article) *WebhookMessage {
+	content := strings.Join(article.Mentions, " ")
+	if text := n.renderContent(article); text != "" {
+		content = strings.TrimSpace(content + "\n" + text)
+	}
+
+	return &WebhookMessage{
+		Content:         content,
+		Embeds:          []Embed{n.createEmbed(article)},
+		AllowedMentions: allowedMentionsFor(article.Mentions),
+	}
+}
+
+// renderContent は、テンプレートのメッセージ本文を作成する
+// テンプレートを使用しない場合や実行に失敗した場合は空文字列を返す
+func (n *Notifier) renderContent(article *models.Article) string {
+	tmpl := n.templates.lookup(article.FeedURL)
+	if tmpl == nil {
+		return ""
+	}
+
+	content, err := tmpl.renderContent(templateArticle(article))
+	if err != nil {
+		logger.Warn("テンプレートの実行に失敗",
+			"template", tmpl.name,
+			"title", article.Title,
+			"error", err)
+		return ""
+	}
+	return content
+}
+
+// createEmbed は、記事からDiscordのEmbedを作成する
+// フィードにテンプレートが指定されている場合は、テンプレートで指定された項目を上書きする
+func (n *Notifier) createEmbed(article *models.Article) Embed {
+	embed := defaultEmbed(article)
+
+	tmpl := n.templates.lookup(article.FeedURL)
+	if tmpl == nil {
+		return embed
+	}
+
+	// テンプレートの実行に失敗した場合はデフォルトの表示で通知する
+	templated := embed
+	if err := tmpl.apply(&templated, templateArticle(article)); err != nil {
+		logger.Warn("テンプレートの実行に失敗したため、デフォルトの表示で通知します",
+			"template", tmpl.name,
+			"title", article.Title,
+			"error", err)
+		return embed
+	}
+
+	return templated
+}
+
+// shortDescription は、記事の説明文を指定された文字数に切り詰める
+// Markdownに変換済みの説明文は、リンクやコードブロックの記法を壊さない位置で切り詰める
+func shortDescription(article *models.Article, maxLength int) string {
+	if article.DescriptionIsMarkdown {
+		return htmlconv.TruncateMarkdown(article.Description, maxLength)
+	}
+	return article.GetShortDescription(maxLength)
+}
+
+// defaultEmbed は、記事からデフォルトの表示のEmbedを作成する
+// フィードから取得したタイトル・説明文はMarkdown記号とメンションをエスケープする
+func defaultEmbed(article *models.Article) Embed {
 	// 説明文を短縮（最大300文字）
-	description := article.GetShortDescription(300)
+	description := escapeDescription(shortDescription(article, 300), article.DescriptionIsMarkdown)
 
 	// カテゴリに応じた色を取得
 	color := getCategoryColor(article.Category)
 
 	// Embedを作成
 	embed := Embed{
-		Title:       article.Title,
+		Title:       escapeFeedText(article.Title),
 		URL:         article.URL,
 		Description: description,
 		Color:       color,
@@ -155,7 +237,7 @@ func (n *Notifier) createMessage(article *models.Article) *WebhookMessage {
 	// 著者情報があれば追加
 	if article.Author != "" {
 		embed.Author = &EmbedAuthor{
-			Name: article.Author,
+			Name: htmlconv.EscapeMentions(article.Author),
 		}
 	}
 
@@ -166,79 +248,199 @@ func (n *Notifier) createMessage(article *models.Article) *WebhookMessage {
 		}
 	}
 
-	return &WebhookMessage{
-		Embeds: []Embed{embed},
-	}
+	return embed
 }
 
 // sendWithRetry は、リトライ付きでメッセージを送信する
-func (n *Notifier) sendWithRetry(ctx context.Context, message *WebhookMessage) error {
-	var lastErr error
+func (n *Notifier) sendWithRetry(ctx context.Context, message *WebhookMessage) (*PostedMessage, error) {
+	return n.requestWithRetry(ctx, http.MethodPost, withWait(n.webhookURL), message)
+}
 
-	for attempt := 0; attempt < n.maxRetries; attempt++ {
-		if attempt > 0 {
-			logger.Debug("Discord送信をリトライ", "attempt", attempt+1)
-			select {
-			case <-time.After(n.retryDelay):
-			case <-ctx.Done():
-				return ctx.Err()
+// requestWithRetry は、リトライ付きでメッセージをWebhookのAPIに送信する（投稿・編集・削除）
+// 送信前にメッセージをDiscordの制限（文字数・フィールド数）に収まるように切り詰める（削除の場合 message は nil）
+// レート制限（429）の場合はDiscordが指定した時間だけ待機して再送し、通常のリトライ回数には数えない
+func (n *Notifier) requestWithRetry(ctx context.Context, method, requestURL string, message *WebhookMessage) (*PostedMessage, error) {
+	// Discordの制限を超える項目は切り詰める（超えたまま送信すると 400 Bad Request になる）
+	if message != nil && normalizeMessage(message) {
+		logger.Debug("Discordの制限に合わせてメッセージを切り詰めました")
+	}
+
+	// 許可するメンションが指定されていないメッセージは、すべてのメンションを通知しない
+	if message != nil && message.AllowedMentions == nil {
+		message.AllowedMentions = allowedMentionsFor(nil)
+	}
+
+	var lastErr error
+	attempt := 0
+	rateLimited := 0
+
+	for attempt < n.maxRetries {
+		// レート制限が解除されるまで待機（上限を超える場合は送信を諦める）
+		if err := n.limiter.Wait(ctx, n.webhookURL, n.maxRateLimitWait); err != nil {
+			var rlErr *RateLimitError
+			if errors.As(err, &rlErr) {
+				return nil, fmt.Errorf("giving up after rate limit: %w", err)
 			}
+			return nil, err
 		}
 
-		err := n.send(ctx, message)
+		posted, err := n.request(ctx, method, requestURL, message)
 		if err == nil {
-			return nil
+			return posted, nil
+		}
+
+		var rlErr *RateLimitError
+		if errors.As(err, &rlErr) {
+			rateLimited++
+			if rateLimited > maxRateLimitRetries || rlErr.RetryAfter > n.maxRateLimitWait {
+				return nil, fmt.Errorf("giving up after rate limit: %w", err)
+			}
+
+			logger.Warn("Discordのレート制限に達しました",
+				"retry_after", rlErr.RetryAfter,
+				"global", rlErr.Global)
+			continue
+		}
+
+		// 対象のメッセージが存在しない場合はリトライしても成功しない
+		if isNotFound(err) {
+			return nil, err
 		}
 
+		attempt++
 		lastErr = err
 		logger.Warn("Discord送信に失敗",
-			"attempt", attempt+1,
+			"attempt", attempt,
 			"max_retries", n.maxRetries,
 			"error", err)
+
+		if attempt < n.maxRetries {
+			logger.Debug("Discord送信をリトライ", "attempt", attempt+1)
+			select {
+			case <-time.After(n.retryDelay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
 	}
 
-	return fmt.Errorf("failed after %d retries: %w", n.maxRetries, lastErr)
+	return nil, fmt.Errorf("failed after %d retries: %w", n.maxRetries, lastErr)
 }
 
-// send は、メッセージをDiscordに送信する
-func (n *Notifier) send(ctx context.Context, message *WebhookMessage) error {
-	// JSONにエンコード
-	jsonData, err := json.Marshal(message)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+// APIError は、Discord APIがエラーのステータスコードを返したことを表すエラー
+type APIError struct {
+	// StatusCode はHTTPステータスコード
+	StatusCode int
+
+	// Body はレスポンスボディ
+	Body string
+}
+
+// Error は、エラーメッセージを返す
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Discord API returned error: status=%d, body=%s", e.StatusCode, e.Body)
+}
+
+// isNotFound は、エラーが 404 Not Found（削除済みのメッセージなど）かどうかを返す
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// request は、メッセージをWebhookのAPIに送信する
+// 投稿（?wait=true）・編集のレスポンスから、メッセージ（メッセージID・チャンネルID）を返す
+// レスポンスにメッセージが含まれない場合（204 No Content など）は nil を返す
+func (n *Notifier) request(ctx context.Context, method, requestURL string, message *WebhookMessage) (*PostedMessage, error) {
+	// JSONにエンコード（削除の場合はボディなし）
+	var payload io.Reader
+	if message != nil {
+		jsonData, err := json.Marshal(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+		payload = bytes.NewReader(jsonData)
 	}
 
-	logger.Debug("Discord Webhookに送信中", "url", n.webhookURL)
+	logger.Debug("Discord Webhookに送信中", "method", method, "url", n.webhookURL)
 
 	// HTTPリクエストを作成
-	req, err := http.NewRequestWithContext(ctx, "POST", n.webhookURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, payload)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	if message != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	// リクエスト送信
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// レスポンスボディを読み取り
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// レート制限ヘッダーを記録
+	n.limiter.Update(n.webhookURL, resp.Header, time.Now())
+
+	// レート制限
+	if resp.StatusCode == http.StatusTooManyRequests {
+		rlErr := parseRateLimitError(resp.Header, body)
+		n.limiter.Limit(n.webhookURL, rlErr, time.Now())
+		return nil, rlErr
 	}
 
 	// ステータスコードをチェック
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("Discord API returned error: status=%d, body=%s", resp.StatusCode, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	logger.Debug("Discord Webhookへの送信が成功", "status", resp.StatusCode)
 
-	return nil
+	// 投稿されたメッセージを解析（送信は成功しているため、解析に失敗してもエラーにしない）
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	var posted PostedMessage
+	if err := json.Unmarshal(body, &posted); err != nil || posted.ID == "" {
+		logger.Warn("Discordのレスポンスからメッセージを取得できませんでした", "error", err)
+		return nil, nil
+	}
+
+	return &posted, nil
+}
+
+// withWait は、Webhook URLに wait=true を付与する
+// 既存のクエリ（thread_id など）は維持する
+func withWait(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+
+	query := u.Query()
+	query.Set("wait", "true")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// recordPostedMessage は、投稿されたメッセージのIDを記事に記録する
+func recordPostedMessage(posted *PostedMessage, articles ...*models.Article) {
+	if posted == nil {
+		return
+	}
+	for _, article := range articles {
+		article.MessageID = posted.ID
+		article.ChannelID = posted.ChannelID
+		article.WebhookID = posted.WebhookID
+	}
 }
 
 // getCategoryColor は、カテゴリに応じた色コードを返す
@@ -258,6 +460,49 @@ func getCategoryColor(category string) int {
 	return 5793522
 }
 
+// SetHTTPClient は、Discord通知に使用するHTTPクライアントを設定する
+// フィード取得と同じトランスポートのクライアントを渡すことで、コネクションプールを共有できる
+func (n *Notifier) SetHTTPClient(client *http.Client) {
+	if client != nil {
+		n.client = client
+	}
+}
+
+// WaitInterval は、次の送信の前に通知間隔（rateLimit）だけ待機する
+// Webhookのレート制限の情報がある場合は、送信時にDiscordの制限に従って待機するため待機しない
+func (n *Notifier) WaitInterval(ctx context.Context) error {
+	if n.rateLimit <= 0 || n.limiter.HasBucket(n.webhookURL) {
+		return nil
+	}
+
+	select {
+	case <-time.After(n.rateLimit):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetRateLimiter は、Discordのレート制限の状態を設定する
+// 同じRateLimiterを複数のNotifierで共有することで、Webhookごとの制限を実行全体で守れる
+func (n *Notifier) SetRateLimiter(limiter *RateLimiter) {
+	if limiter != nil {
+		n.limiter = limiter
+	}
+}
+
+// SetMaxRateLimitWait は、レート制限で待機する時間の上限を設定する
+func (n *Notifier) SetMaxRateLimitWait(duration time.Duration) {
+	if duration > 0 {
+		n.maxRateLimitWait = duration
+	}
+}
+
+// SetTemplates は、フィードごとの通知メッセージのテンプレートを設定する
+func (n *Notifier) SetTemplates(templates *TemplateSet) {
+	n.templates = templates
+}
+
 // SetRateLimit は、レート制限間隔を設定する
 func (n *Notifier) SetRateLimit(duration time.Duration) {
 	n.rateLimit = duration
@@ -107,6 +107,18 @@ func TestCreateMessage(t *testing.T) {
 	if embed.Footer == nil {
 		t.Error("Footer should not be nil")
 	}
+
+	// メンションがない場合は本文なし
+	if message.Content != "" {
+		t.Errorf("Content = %q, want empty", message.Content)
+	}
+
+	// ルーティングルールのメンションは本文に付与される
+	article.Mentions = []string{"<@&100>", "<@200>"}
+	message = notifier.createMessage(article)
+	if message.Content != "<@&100> <@200>" {
+		t.Errorf("Content = %q, want %q", message.Content, "<@&100> <@200>")
+	}
 }
 
 // TestGetCategoryColor は、カテゴリ別色分けをテストする
@@ -304,3 +316,66 @@ func TestSetters(t *testing.T) {
 		t.Errorf("retryDelay = %v, want %v", notifier.retryDelay, newRetryDelay)
 	}
 }
+
+// TestSendArticleMessageID は、?wait=true で投稿したメッセージのIDを記録することをテストする
+func TestSendArticleMessageID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("wait") != "true" {
+			t.Errorf("wait = %q, want true", r.URL.Query().Get("wait"))
+		}
+		if r.URL.Query().Get("thread_id") != "123" {
+			t.Errorf("thread_id = %q, want 123", r.URL.Query().Get("thread_id"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "1001", "channel_id": "2002", "webhook_id": "3003", "content": ""}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token?thread_id=123", 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Test Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+		Category:    "Tech",
+	}
+
+	if err := notifier.SendArticle(context.Background(), article); err != nil {
+		t.Fatalf("SendArticle() error = %v", err)
+	}
+
+	if article.MessageID != "1001" || article.ChannelID != "2002" || article.WebhookID != "3003" {
+		t.Errorf("MessageID = %q, ChannelID = %q, WebhookID = %q, want 1001, 2002, 3003",
+			article.MessageID, article.ChannelID, article.WebhookID)
+	}
+}
+
+// TestSendArticleNoContent は、レスポンスにメッセージがない場合も成功として扱うことをテストする
+func TestSendArticleNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Test Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+	}
+
+	if err := notifier.SendArticle(context.Background(), article); err != nil {
+		t.Fatalf("SendArticle() error = %v", err)
+	}
+	if article.MessageID != "" {
+		t.Errorf("MessageID = %q, want empty", article.MessageID)
+	}
+}
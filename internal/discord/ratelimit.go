@@ -0,0 +1,198 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+)
+
+// defaultMaxRateLimitWait は、レート制限で待機する時間の上限のデフォルト値
+// これより長い待機を求められた場合は送信を諦める
+const defaultMaxRateLimitWait = 60 * time.Second
+
+// maxRateLimitRetries は、レート制限（429）による再送の上限
+// 通常のリトライ回数とは別に数える
+const maxRateLimitRetries = 5
+
+// RateLimitError は、Discordのレート制限（429 Too Many Requests）を表すエラー
+type RateLimitError struct {
+	// RetryAfter はDiscordが指定した待機時間
+	RetryAfter time.Duration
+
+	// Global はグローバルレート制限かどうか
+	Global bool
+}
+
+// Error は、エラーメッセージを返す
+func (e *RateLimitError) Error() string {
+	scope := "webhook"
+	if e.Global {
+		scope = "global"
+	}
+	return fmt.Sprintf("rate limited by Discord (%s): retry after %s", scope, e.RetryAfter)
+}
+
+// RateLimiter は、Discordのレート制限ヘッダーに従って送信を待機させる構造体
+// Webhook URLごとのバケットとグローバルレート制限を管理する
+// 複数のNotifierで共有することで、同じWebhookへの送信をまとめて制御できる
+type RateLimiter struct {
+	mu sync.Mutex
+
+	// buckets はWebhook URLごとのレート制限の状態
+	buckets map[string]*rateLimitBucket
+
+	// globalResetAt はグローバルレート制限が解除される時刻
+	globalResetAt time.Time
+}
+
+// rateLimitBucket は、1つのWebhookのレート制限の状態
+type rateLimitBucket struct {
+	// remaining はリセットまでに送信できる残り回数
+	remaining int
+
+	// resetAt は残り回数がリセットされる時刻
+	resetAt time.Time
+}
+
+// rateLimitResponse は、429のレスポンスボディ
+type rateLimitResponse struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
+
+// NewRateLimiter は、新しいレート制限管理を作成する
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]*rateLimitBucket),
+	}
+}
+
+// Wait は、指定されたWebhookに送信できるまで待機する
+// 待機時間が maxWait を超える場合は待機せずに RateLimitError を返す（maxWait が0以下の場合は上限なし）
+func (l *RateLimiter) Wait(ctx context.Context, key string, maxWait time.Duration) error {
+	delay, global := l.delay(key, time.Now())
+	if delay <= 0 {
+		return nil
+	}
+	if maxWait > 0 && delay > maxWait {
+		return &RateLimitError{RetryAfter: delay, Global: global}
+	}
+
+	logger.Debug("Discordのレート制限により待機", "delay", delay)
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// HasBucket は、指定されたWebhookのレート制限の状態（ヘッダーまたは429による情報）があるかを返す
+func (l *RateLimiter) HasBucket(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.buckets[key]
+	return ok
+}
+
+// delay は、指定されたWebhookに送信できるまでの待機時間と、グローバルレート制限による待機かどうかを返す
+func (l *RateLimiter) delay(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	resetAt := l.globalResetAt
+	global := true
+	if b, ok := l.buckets[key]; ok && b.remaining <= 0 && b.resetAt.After(resetAt) {
+		resetAt = b.resetAt
+		global = false
+	}
+
+	return resetAt.Sub(now), global
+}
+
+// Update は、レスポンスのレート制限ヘッダーでバケットの状態を更新する
+// X-RateLimit-Remaining と X-RateLimit-Reset-After の両方がある場合のみ更新する
+func (l *RateLimiter) Update(key string, header http.Header, now time.Time) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	resetAfter, ok := parseSeconds(header.Get("X-RateLimit-Reset-After"))
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.buckets[key] = &rateLimitBucket{
+		remaining: remaining,
+		resetAt:   now.Add(resetAfter),
+	}
+}
+
+// Limit は、429を受け取った場合に待機時間を記録する
+func (l *RateLimiter) Limit(key string, rlErr *RateLimitError, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	resetAt := now.Add(rlErr.RetryAfter)
+	if rlErr.Global {
+		if resetAt.After(l.globalResetAt) {
+			l.globalResetAt = resetAt
+		}
+		return
+	}
+
+	l.buckets[key] = &rateLimitBucket{remaining: 0, resetAt: resetAt}
+}
+
+// parseRateLimitError は、429のレスポンスから待機時間とグローバルかどうかを取得する
+// 待機時間はボディの retry_after > Retry-After ヘッダー > X-RateLimit-Reset-After の順に使用する
+func parseRateLimitError(header http.Header, body []byte) *RateLimitError {
+	rlErr := &RateLimitError{
+		Global: strings.EqualFold(header.Get("X-RateLimit-Global"), "true") ||
+			header.Get("X-RateLimit-Scope") == "global",
+	}
+
+	var resp rateLimitResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.RetryAfter > 0 {
+		rlErr.RetryAfter = time.Duration(resp.RetryAfter * float64(time.Second))
+		rlErr.Global = rlErr.Global || resp.Global
+		return rlErr
+	}
+
+	for _, name := range []string{"Retry-After", "X-RateLimit-Reset-After"} {
+		if d, ok := parseSeconds(header.Get(name)); ok {
+			rlErr.RetryAfter = d
+			return rlErr
+		}
+	}
+
+	// 待機時間が不明な場合は1秒待機する
+	rlErr.RetryAfter = time.Second
+	return rlErr
+}
+
+// parseSeconds は、秒数（小数を含む）を表す文字列を解析する
+func parseSeconds(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds * float64(time.Second)), true
+}
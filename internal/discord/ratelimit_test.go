@@ -0,0 +1,223 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestParseRateLimitError は、429レスポンスの解析をテストする
+func TestParseRateLimitError(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     map[string]string
+		body       string
+		wantDelay  time.Duration
+		wantGlobal bool
+	}{
+		{
+			name:      "ボディのretry_after",
+			header:    map[string]string{"Retry-After": "3"},
+			body:      `{"message": "You are being rate limited.", "retry_after": 1.5, "global": false}`,
+			wantDelay: 1500 * time.Millisecond,
+		},
+		{
+			name:       "グローバルレート制限（ボディ）",
+			body:       `{"message": "You are being rate limited.", "retry_after": 2, "global": true}`,
+			wantDelay:  2 * time.Second,
+			wantGlobal: true,
+		},
+		{
+			name:       "グローバルレート制限（ヘッダー）",
+			header:     map[string]string{"X-RateLimit-Global": "true", "Retry-After": "4"},
+			body:       `<html>Cloudflare</html>`,
+			wantDelay:  4 * time.Second,
+			wantGlobal: true,
+		},
+		{
+			name:      "Reset-Afterヘッダー",
+			header:    map[string]string{"X-RateLimit-Reset-After": "0.250"},
+			wantDelay: 250 * time.Millisecond,
+		},
+		{
+			name:      "待機時間が不明",
+			wantDelay: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+
+			got := parseRateLimitError(header, []byte(tt.body))
+			if got.RetryAfter != tt.wantDelay {
+				t.Errorf("RetryAfter = %v, want %v", got.RetryAfter, tt.wantDelay)
+			}
+			if got.Global != tt.wantGlobal {
+				t.Errorf("Global = %v, want %v", got.Global, tt.wantGlobal)
+			}
+		})
+	}
+}
+
+// TestRateLimiterDelay は、バケットとグローバル制限による待機時間をテストする
+func TestRateLimiterDelay(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter()
+
+	// 残り回数がある場合は待機しない
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "1")
+	header.Set("X-RateLimit-Reset-After", "2")
+	limiter.Update("webhook-a", header, now)
+	if d, _ := limiter.delay("webhook-a", now); d > 0 {
+		t.Errorf("delay = %v, want 0 when requests remain", d)
+	}
+
+	// 残り回数が0の場合はリセットまで待機
+	header.Set("X-RateLimit-Remaining", "0")
+	limiter.Update("webhook-a", header, now)
+	if d, _ := limiter.delay("webhook-a", now); d != 2*time.Second {
+		t.Errorf("delay = %v, want 2s", d)
+	}
+
+	// 他のWebhookには影響しない
+	if d, _ := limiter.delay("webhook-b", now); d > 0 {
+		t.Errorf("delay for other webhook = %v, want 0", d)
+	}
+
+	// グローバル制限はすべてのWebhookに影響する
+	limiter.Limit("webhook-b", &RateLimitError{RetryAfter: 5 * time.Second, Global: true}, now)
+	if d, _ := limiter.delay("webhook-b", now); d != 5*time.Second {
+		t.Errorf("delay with global limit = %v, want 5s", d)
+	}
+	if d, _ := limiter.delay("webhook-a", now); d != 5*time.Second {
+		t.Errorf("delay with global limit = %v, want 5s", d)
+	}
+}
+
+// TestWaitInterval は、レート制限の情報がない場合のみ通知間隔だけ待機することをテストする
+func TestWaitInterval(t *testing.T) {
+	notifier := NewNotifier("https://discord.com/api/webhooks/1/token", time.Hour)
+
+	// レート制限の情報がない場合は通知間隔だけ待機する（キャンセルで中断）
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := notifier.WaitInterval(ctx); err == nil {
+		t.Error("WaitInterval() should wait for the interval without rate limit headers")
+	}
+
+	// レート制限の情報がある場合は待機しない
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "4")
+	header.Set("X-RateLimit-Reset-After", "2")
+	notifier.limiter.Update(notifier.webhookURL, header, time.Now())
+	if err := notifier.WaitInterval(context.Background()); err != nil {
+		t.Errorf("WaitInterval() error = %v, want nil", err)
+	}
+}
+
+// TestSendWithRateLimit は、429を受け取った場合に指定時間だけ待機して再送することをテストする
+func TestSendWithRateLimit(t *testing.T) {
+	attemptCount := 0
+	var firstAttempt, secondAttempt time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		if attemptCount == 1 {
+			firstAttempt = time.Now()
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset-After", "0.2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.2, "global": false}`))
+			return
+		}
+		secondAttempt = time.Now()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1) // レート制限はリトライ回数に数えない
+	notifier.SetRetryDelay(10 * time.Second)
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Test Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+	}
+
+	if err := notifier.SendArticle(context.Background(), article); err != nil {
+		t.Fatalf("SendArticle() error = %v, want nil", err)
+	}
+
+	if attemptCount != 2 {
+		t.Fatalf("Attempt count = %d, want 2", attemptCount)
+	}
+	if waited := secondAttempt.Sub(firstAttempt); waited < 200*time.Millisecond || waited > 5*time.Second {
+		t.Errorf("waited %v, want about 200ms", waited)
+	}
+}
+
+// TestSendWithRateLimitTooLong は、待機時間が上限を超える場合に諦めることをテストする
+func TestSendWithRateLimitTooLong(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 3600, "global": true}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRateLimitWait(time.Second)
+
+	err := notifier.SendArticle(context.Background(), &models.Article{Title: "Test Article"})
+	if err == nil {
+		t.Fatal("SendArticle() should fail when retry_after exceeds the limit")
+	}
+}
+
+// TestSendAfterRateLimitTooLong は、記録済みのレート制限が上限を超える場合に待機せずに諦めることをテストする
+func TestSendAfterRateLimitTooLong(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 600, "global": false}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRateLimitWait(time.Second)
+
+	if err := notifier.SendArticle(context.Background(), &models.Article{Title: "First"}); err == nil {
+		t.Fatal("SendArticle() should fail when retry_after exceeds the limit")
+	}
+
+	// 2回目の送信は記録済みの待機時間が上限を超えるため、送信も待機もせずに失敗する
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := notifier.SendArticle(ctx, &models.Article{Title: "Second"})
+	var rlErr *RateLimitError
+	if !errors.As(err, &rlErr) {
+		t.Fatalf("SendArticle() error = %v, want RateLimitError", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("SendArticle() blocked for %v", elapsed)
+	}
+	if requestCount != 1 {
+		t.Errorf("request count = %d, want 1", requestCount)
+	}
+}
@@ -0,0 +1,79 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+)
+
+const (
+	// retractedTitlePrefix は取り下げた記事のEmbedのタイトルに付ける目印
+	retractedTitlePrefix = "【取り下げ】"
+
+	// retractedDescription は取り下げた記事のEmbedの説明文
+	retractedDescription = "この記事はフィードから削除されました。"
+
+	// retractedColor は取り下げた記事のEmbedの色（Grey）
+	retractedColor = 9807270
+)
+
+// DeleteMessage は、通知済みのメッセージを削除する
+// messageID は通知時に記録したメッセージID（同じWebhookで投稿したメッセージのみ削除できる）
+// メッセージがすでに削除されている場合（404 Unknown Message）は成功として扱う
+func (n *Notifier) DeleteMessage(ctx context.Context, messageID string) error {
+	if messageID == "" {
+		return fmt.Errorf("message ID is empty")
+	}
+
+	if _, err := n.requestWithRetry(ctx, http.MethodDelete, messageURL(n.webhookURL, messageID), nil); err != nil {
+		if isNotFound(err) {
+			logger.Info("メッセージはすでに削除されています", "message_id", messageID)
+			return nil
+		}
+		return fmt.Errorf("failed to delete message %s: %w", messageID, err)
+	}
+
+	logger.Info("通知済みのメッセージを削除しました", "message_id", messageID)
+
+	return nil
+}
+
+// RetractMessage は、通知済みのメッセージを記事が取り下げられたことを示す表示に編集する
+// 記事へのリンクや説明文は削除し、タイトルのみを残す
+// メッセージがすでに削除されている場合（404 Unknown Message）は成功として扱う
+func (n *Notifier) RetractMessage(ctx context.Context, messageID, title string) error {
+	if messageID == "" {
+		return fmt.Errorf("message ID is empty")
+	}
+
+	message := &WebhookMessage{
+		Embeds: []Embed{
+			{
+				Title:       retractedTitlePrefix + escapeFeedText(title),
+				Description: retractedDescription,
+				Color:       retractedColor,
+				Timestamp:   time.Now().Format(time.RFC3339),
+				Footer: &EmbedFooter{
+					Text: "RSS Discord Notifier",
+				},
+			},
+		},
+	}
+
+	if _, err := n.requestWithRetry(ctx, http.MethodPatch, messageURL(n.webhookURL, messageID), message); err != nil {
+		if isNotFound(err) {
+			logger.Info("メッセージはすでに削除されています", "message_id", messageID)
+			return nil
+		}
+		return fmt.Errorf("failed to retract message %s: %w", messageID, err)
+	}
+
+	logger.Info("通知済みのメッセージを取り下げの表示に編集しました",
+		"title", title,
+		"message_id", messageID)
+
+	return nil
+}
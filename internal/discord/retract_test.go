@@ -0,0 +1,102 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestDeleteMessage は、通知済みのメッセージの削除をテストする
+func TestDeleteMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method = %s, want DELETE", r.Method)
+		}
+		if r.URL.Path != "/api/webhooks/3003/token/messages/1001" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if body, _ := io.ReadAll(r.Body); len(body) != 0 {
+			t.Errorf("body = %q, want empty", body)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token", 0)
+	notifier.SetMaxRetries(1)
+
+	if err := notifier.DeleteMessage(context.Background(), "1001"); err != nil {
+		t.Fatalf("DeleteMessage() error = %v", err)
+	}
+
+	if err := notifier.DeleteMessage(context.Background(), ""); err == nil {
+		t.Error("DeleteMessage() with empty message ID should return error")
+	}
+}
+
+// TestRetractMessage は、通知済みのメッセージの取り下げの表示への編集をテストする
+func TestRetractMessage(t *testing.T) {
+	var received WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %s, want PATCH", r.Method)
+		}
+		if r.URL.Path != "/api/webhooks/3003/token/messages/1001" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "1001", "channel_id": "2002", "webhook_id": "3003"}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token", 0)
+	notifier.SetMaxRetries(1)
+
+	if err := notifier.RetractMessage(context.Background(), "1001", "Embargoed Article"); err != nil {
+		t.Fatalf("RetractMessage() error = %v", err)
+	}
+
+	if len(received.Embeds) != 1 {
+		t.Fatalf("Embeds length = %d, want 1", len(received.Embeds))
+	}
+	embed := received.Embeds[0]
+	if embed.Title != "【取り下げ】Embargoed Article" || embed.Description != retractedDescription {
+		t.Errorf("Title = %q, Description = %q", embed.Title, embed.Description)
+	}
+	// 削除された記事へのリンクは残さない
+	if embed.URL != "" {
+		t.Errorf("URL = %q, want empty", embed.URL)
+	}
+}
+
+// TestRetractDeletedMessage は、すでに削除されたメッセージの取り下げを成功として扱うことをテストする
+func TestRetractDeletedMessage(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Unknown Message", "code": 10008}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token", 0)
+	notifier.SetRetryDelay(0)
+
+	if err := notifier.DeleteMessage(context.Background(), "1001"); err != nil {
+		t.Errorf("DeleteMessage() error = %v, want nil", err)
+	}
+	if err := notifier.RetractMessage(context.Background(), "1001", "Embargoed Article"); err != nil {
+		t.Errorf("RetractMessage() error = %v, want nil", err)
+	}
+
+	// 404 はリトライしない
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+}
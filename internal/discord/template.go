@@ -0,0 +1,288 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TemplateSet は、フィードごとの通知メッセージのテンプレートを管理する構造体
+type TemplateSet struct {
+	// feeds はフィードURLごとのテンプレート（テンプレートを使用するフィードのみ）
+	feeds map[string]*messageTemplate
+}
+
+// messageTemplate は、パース済みの通知メッセージのテンプレート
+// 指定されていない項目は nil（デフォルトの表示を使用する）
+type messageTemplate struct {
+	name        string
+	content     *template.Template
+	title       *template.Template
+	description *template.Template
+	footer      *template.Template
+	author      *template.Template
+
+	// fields は hasFields が true の場合にデフォルトのフィールドを置き換える
+	fields    []*fieldTemplate
+	hasFields bool
+}
+
+// fieldTemplate は、パース済みのEmbedのフィールドのテンプレート
+type fieldTemplate struct {
+	name   *template.Template
+	value  *template.Template
+	inline bool
+}
+
+// NewTemplateSet は、設定からフィードごとのテンプレートを作成する
+// フィードの template はカテゴリの template より優先される
+// テンプレートの構文エラーや存在しないフィールドの参照はここでエラーになる
+func NewTemplateSet(cfg *models.Config) (*TemplateSet, error) {
+	loc := time.Local
+	if cfg.Notification != nil && cfg.Notification.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Notification.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", cfg.Notification.Timezone, err)
+		}
+		loc = l
+	}
+	funcs := templateFuncs(loc)
+
+	templates := make(map[string]*messageTemplate)
+	for name, mt := range cfg.Templates {
+		if mt == nil {
+			continue
+		}
+		t, err := parseMessageTemplate(name, mt, funcs)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		templates[name] = t
+	}
+
+	lookup := func(name string) (*messageTemplate, error) {
+		t, ok := templates[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown template: %s", name)
+		}
+		return t, nil
+	}
+
+	for categoryName, category := range cfg.Categories {
+		if category == nil || category.Template == "" {
+			continue
+		}
+		if _, err := lookup(category.Template); err != nil {
+			return nil, fmt.Errorf("category %s: %w", categoryName, err)
+		}
+	}
+
+	s := &TemplateSet{feeds: make(map[string]*messageTemplate)}
+	for _, fc := range cfg.Feeds {
+		name := fc.Template
+		if category := cfg.Categories[fc.Category]; name == "" && category != nil {
+			name = category.Template
+		}
+		if name == "" {
+			continue
+		}
+
+		t, err := lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", fc.Name, err)
+		}
+		s.feeds[fc.URL] = t
+	}
+
+	return s, nil
+}
+
+// lookup は、指定されたフィードのテンプレートを返す（テンプレートを使用しない場合は nil）
+func (s *TemplateSet) lookup(feedURL string) *messageTemplate {
+	if s == nil {
+		return nil
+	}
+	return s.feeds[feedURL]
+}
+
+// parseMessageTemplate は、テンプレートの各項目をパースし、サンプルの記事で実行できるか確認する
+func parseMessageTemplate(name string, mt *models.MessageTemplate, funcs template.FuncMap) (*messageTemplate, error) {
+	t := &messageTemplate{name: name}
+
+	parse := func(item, text string) (*template.Template, error) {
+		if text == "" {
+			return nil, nil
+		}
+		tmpl, err := template.New(name + "." + item).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := execute(tmpl, sampleArticle); err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+
+	var err error
+	if t.content, err = parse("content", mt.Content); err != nil {
+		return nil, err
+	}
+	if t.title, err = parse("title", mt.Title); err != nil {
+		return nil, err
+	}
+	if t.description, err = parse("description", mt.Description); err != nil {
+		return nil, err
+	}
+	if t.footer, err = parse("footer", mt.Footer); err != nil {
+		return nil, err
+	}
+	if t.author, err = parse("author", mt.Author); err != nil {
+		return nil, err
+	}
+
+	if mt.Fields != nil {
+		t.hasFields = true
+		for i, field := range mt.Fields {
+			if field == nil || field.Name == "" || field.Value == "" {
+				return nil, fmt.Errorf("field %d: name and value are required", i)
+			}
+			f := &fieldTemplate{inline: field.Inline}
+			if f.name, err = parse(fmt.Sprintf("fields[%d].name", i), field.Name); err != nil {
+				return nil, err
+			}
+			if f.value, err = parse(fmt.Sprintf("fields[%d].value", i), field.Value); err != nil {
+				return nil, err
+			}
+			t.fields = append(t.fields, f)
+		}
+	}
+
+	return t, nil
+}
+
+// apply は、テンプレートで指定された項目でEmbedを上書きする
+func (t *messageTemplate) apply(embed *Embed, article *models.Article) error {
+	var err error
+
+	if t.title != nil {
+		if embed.Title, err = execute(t.title, article); err != nil {
+			return err
+		}
+	}
+
+	if t.description != nil {
+		if embed.Description, err = execute(t.description, article); err != nil {
+			return err
+		}
+	}
+
+	if t.hasFields {
+		fields := make([]EmbedField, 0, len(t.fields))
+		for _, f := range t.fields {
+			name, err := execute(f.name, article)
+			if err != nil {
+				return err
+			}
+			value, err := execute(f.value, article)
+			if err != nil {
+				return err
+			}
+			// 名前または値が空のフィールドはDiscordが受け付けないため表示しない
+			if strings.TrimSpace(name) == "" || strings.TrimSpace(value) == "" {
+				continue
+			}
+			fields = append(fields, EmbedField{Name: name, Value: value, Inline: f.inline})
+		}
+		embed.Fields = fields
+	}
+
+	if t.footer != nil {
+		text, err := execute(t.footer, article)
+		if err != nil {
+			return err
+		}
+		embed.Footer = nil
+		if text != "" {
+			embed.Footer = &EmbedFooter{Text: text}
+		}
+	}
+
+	if t.author != nil {
+		name, err := execute(t.author, article)
+		if err != nil {
+			return err
+		}
+		embed.Author = nil
+		if name != "" {
+			embed.Author = &EmbedAuthor{Name: name}
+		}
+	}
+
+	return nil
+}
+
+// renderContent は、メッセージ本文のテンプレートを実行する（指定されていない場合は空文字列）
+func (t *messageTemplate) renderContent(article *models.Article) (string, error) {
+	if t.content == nil {
+		return "", nil
+	}
+	return execute(t.content, article)
+}
+
+// execute は、記事を渡してテンプレートを実行し、前後の空白を除いた結果を返す
+func execute(tmpl *template.Template, article *models.Article) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, article); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// templateFuncs は、テンプレートで使用できる関数を返す
+//
+//	truncate N s         s を最大N文字に切り詰める（超えた場合は末尾を ... にする）
+//	date LAYOUT t        日時をGoのレイアウト形式で書式化する（notification.timezone のタイムゾーン）
+//	escapeMarkdown s     DiscordのMarkdown記号をエスケープする
+//	join SEP list        文字列のリスト（Tags など）を連結する
+//	default DEF s        s が空の場合に DEF を返す
+func templateFuncs(loc *time.Location) template.FuncMap {
+	return template.FuncMap{
+		"truncate": func(maxLength int, s string) string {
+			return truncateRunes(s, maxLength)
+		},
+		"date": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.In(loc).Format(layout)
+		},
+		"escapeMarkdown": htmlconv.EscapeMarkdown,
+		"join": func(sep string, values []string) string {
+			return strings.Join(values, sep)
+		},
+		"default": func(def, s string) string {
+			if strings.TrimSpace(s) == "" {
+				return def
+			}
+			return s
+		},
+	}
+}
+
+// sampleArticle は、テンプレートの検証に使用する記事
+var sampleArticle = &models.Article{
+	ID:          "sample",
+	Title:       "Sample Article",
+	URL:         "https://example.com/sample",
+	Description: "Sample description",
+	Author:      "Sample Author",
+	PublishedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	FeedName:    "Sample Feed",
+	FeedURL:     "https://example.com/feed",
+	Category:    "Tech",
+	Tags:        []string{"sample"},
+}
@@ -0,0 +1,157 @@
+package discord
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// newTemplateConfig は、テンプレートを使用するテスト用の設定を作成する
+func newTemplateConfig(templates map[string]*models.MessageTemplate) *models.Config {
+	return &models.Config{
+		Notification: &models.NotificationConfig{Timezone: "Asia/Tokyo"},
+		Feeds: []*models.FeedConfig{
+			{Name: "Feed", URL: "https://example.com/feed", Category: "Tech", Template: "compact"},
+			{Name: "Category", URL: "https://example.com/category", Category: "Blog"},
+			{Name: "Default", URL: "https://example.com/default", Category: "News"},
+		},
+		Categories: map[string]*models.CategoryConfig{
+			"Blog": {Template: "compact"},
+		},
+		Templates: templates,
+	}
+}
+
+// TestNewTemplateSet は、フィードとカテゴリのテンプレートの選択と検証をテストする
+func TestNewTemplateSet(t *testing.T) {
+	set, err := NewTemplateSet(newTemplateConfig(map[string]*models.MessageTemplate{
+		"compact": {Title: "{{.FeedName}}: {{.Title}}"},
+	}))
+	if err != nil {
+		t.Fatalf("NewTemplateSet() error = %v", err)
+	}
+
+	if set.lookup("https://example.com/feed") == nil {
+		t.Error("feed template should be used")
+	}
+	if set.lookup("https://example.com/category") == nil {
+		t.Error("category template should be used")
+	}
+	if set.lookup("https://example.com/default") != nil {
+		t.Error("feed without template should use the default layout")
+	}
+
+	tests := []struct {
+		name      string
+		templates map[string]*models.MessageTemplate
+	}{
+		{"存在しないテンプレート", map[string]*models.MessageTemplate{"other": {Title: "{{.Title}}"}}},
+		{"構文エラー", map[string]*models.MessageTemplate{"compact": {Title: "{{.Title"}}},
+		{"存在しないフィールド", map[string]*models.MessageTemplate{"compact": {Title: "{{.Headline}}"}}},
+		{"存在しない関数", map[string]*models.MessageTemplate{"compact": {Title: "{{upper .Title}}"}}},
+		{"値のないフィールド", map[string]*models.MessageTemplate{"compact": {Fields: []*models.TemplateField{{Name: "Feed"}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTemplateSet(newTemplateConfig(tt.templates)); err == nil {
+				t.Error("NewTemplateSet() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestCreateMessageWithTemplate は、テンプレートによるメッセージの作成をテストする
+func TestCreateMessageWithTemplate(t *testing.T) {
+	set, err := NewTemplateSet(newTemplateConfig(map[string]*models.MessageTemplate{
+		"compact": {
+			Content:     "新着: {{.Title}}",
+			Title:       "[{{.Category}}] {{.Title | escapeMarkdown}}",
+			Description: "{{.Description | truncate 10}}",
+			Fields: []*models.TemplateField{
+				{Name: "公開日時", Value: `{{.PublishedAt | date "2006-01-02 15:04"}}`, Inline: true},
+				{Name: "タグ", Value: `{{join ", " .Tags}}`},
+			},
+			Footer: "{{.FeedName}}",
+			Author: `{{default "不明" .Author}}`,
+		},
+	}))
+	if err != nil {
+		t.Fatalf("NewTemplateSet() error = %v", err)
+	}
+
+	notifier := NewNotifier("https://test.com", 0)
+	notifier.SetTemplates(set)
+
+	article := &models.Article{
+		Title:       "Go *1.22*",
+		URL:         "https://example.com/article",
+		Description: "とても長い説明文です。とても長い説明文です。",
+		PublishedAt: time.Date(2025, 11, 13, 0, 30, 0, 0, time.UTC),
+		FeedName:    "Go Blog",
+		FeedURL:     "https://example.com/feed",
+		Category:    "Tech",
+		Mentions:    []string{"<@&100>"},
+	}
+
+	message := notifier.createMessage(article)
+	embed := message.Embeds[0]
+
+	if message.Content != "<@&100>\n新着: Go *1.22*" {
+		t.Errorf("Content = %q", message.Content)
+	}
+	if embed.Title != `[Tech] Go \*1.22\*` {
+		t.Errorf("Title = %q", embed.Title)
+	}
+	if embed.Description != "とても長い説明..." {
+		t.Errorf("Description = %q", embed.Description)
+	}
+	// タグが空のフィールドは表示しない
+	if len(embed.Fields) != 1 {
+		t.Fatalf("Fields length = %d, want 1", len(embed.Fields))
+	}
+	// notification.timezone（Asia/Tokyo）で書式化される
+	if embed.Fields[0].Value != "2025-11-13 09:30" || !embed.Fields[0].Inline {
+		t.Errorf("Fields[0] = %+v", embed.Fields[0])
+	}
+	if embed.Footer == nil || embed.Footer.Text != "Go Blog" {
+		t.Errorf("Footer = %+v", embed.Footer)
+	}
+	if embed.Author == nil || embed.Author.Name != "不明" {
+		t.Errorf("Author = %+v", embed.Author)
+	}
+	// テンプレートで指定していない項目はデフォルトのまま
+	if embed.URL != article.URL || embed.Color != getCategoryColor("Tech") {
+		t.Errorf("URL = %v, Color = %v", embed.URL, embed.Color)
+	}
+}
+
+// TestCreateEmbedTemplateFallback は、テンプレートの実行に失敗した場合にデフォルトの表示になることをテストする
+func TestCreateEmbedTemplateFallback(t *testing.T) {
+	set, err := NewTemplateSet(newTemplateConfig(map[string]*models.MessageTemplate{
+		"compact": {Title: "{{index .Tags 0}}: {{.Title}}"},
+	}))
+	if err != nil {
+		t.Fatalf("NewTemplateSet() error = %v", err)
+	}
+
+	notifier := NewNotifier("https://test.com", 0)
+	notifier.SetTemplates(set)
+
+	// タグがない記事では index が失敗する
+	article := &models.Article{
+		Title:    "Title",
+		URL:      "https://example.com/article",
+		FeedName: "Feed",
+		FeedURL:  "https://example.com/feed",
+	}
+
+	embed := notifier.createEmbed(article)
+	if embed.Title != "Title" {
+		t.Errorf("Title = %q, want %q", embed.Title, "Title")
+	}
+	if len(embed.Fields) != 3 {
+		t.Errorf("Fields length = %d, want 3", len(embed.Fields))
+	}
+}
@@ -0,0 +1,105 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+const (
+	// updatedTitlePrefix は更新された記事のEmbedのタイトルに付ける目印
+	updatedTitlePrefix = "【更新】"
+
+	// updateNoticeContent は更新を知らせる追加の通知のメッセージ本文
+	updateNoticeContent = "🔄 記事が更新されました"
+)
+
+// EditMessage は、通知済みのメッセージを更新後の記事の内容に編集する
+// メッセージ本文（メンションなど）は変更せず、Embedのみを置き換える
+// messageID は通知時に記録したメッセージID（同じWebhookで投稿したメッセージのみ編集できる）
+// メッセージが削除されている場合（404 Unknown Message）はリトライせずにエラーを返す
+func (n *Notifier) EditMessage(ctx context.Context, messageID string, article *models.Article) error {
+	if messageID == "" {
+		return fmt.Errorf("message ID is empty")
+	}
+
+	message := &WebhookMessage{
+		Embeds: []Embed{n.createUpdatedEmbed(article)},
+	}
+
+	if _, err := n.requestWithRetry(ctx, http.MethodPatch, messageURL(n.webhookURL, messageID), message); err != nil {
+		return fmt.Errorf("failed to edit message for article %s: %w", article.Title, err)
+	}
+
+	logger.Info("更新された記事の通知を編集しました",
+		"title", article.Title,
+		"feed", article.FeedName,
+		"message_id", messageID)
+
+	return nil
+}
+
+// SendUpdate は、記事が更新されたことを新しいメッセージでDiscordに通知する
+// 更新の通知ではメンションしない
+func (n *Notifier) SendUpdate(ctx context.Context, article *models.Article) error {
+	message := &WebhookMessage{
+		Content: updateNoticeContent,
+		Embeds:  []Embed{n.createUpdatedEmbed(article)},
+	}
+
+	if _, err := n.sendWithRetry(ctx, message); err != nil {
+		return fmt.Errorf("failed to send update for article %s: %w", article.Title, err)
+	}
+
+	logger.Info("記事の更新を通知しました",
+		"title", article.Title,
+		"feed", article.FeedName)
+
+	return nil
+}
+
+// createUpdatedEmbed は、更新された記事のEmbedを作成する（タイトルに更新の目印を付ける）
+func (n *Notifier) createUpdatedEmbed(article *models.Article) Embed {
+	embed := n.createEmbed(article)
+	embed.Title = updatedTitlePrefix + embed.Title
+	return embed
+}
+
+// messageURL は、Webhookで投稿したメッセージを操作するURLを返す
+// （https://discord.com/api/webhooks/{id}/{token}/messages/{message_id}）
+// スレッドに投稿するWebhook URLの thread_id は維持し、wait は取り除く
+func messageURL(webhookURL, messageID string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return strings.TrimSuffix(webhookURL, "/") + "/messages/" + messageID
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/messages/" + url.PathEscape(messageID)
+	query := u.Query()
+	query.Del("wait")
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// WebhookID は、Webhook URL（https://discord.com/api/webhooks/{id}/{token}）からWebhookのIDを返す
+// 形式が異なる場合は空文字列を返す
+func WebhookID(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+2 < len(segments); i++ {
+		if segments[i] == "webhooks" {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
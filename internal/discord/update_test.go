@@ -0,0 +1,180 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestMessageURL は、メッセージを操作するURLの作成をテストする
+func TestMessageURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		webhookURL string
+		want       string
+	}{
+		{
+			name:       "通常のWebhook URL",
+			webhookURL: "https://discord.com/api/webhooks/123/token",
+			want:       "https://discord.com/api/webhooks/123/token/messages/1001",
+		},
+		{
+			name:       "スレッドのWebhook URL",
+			webhookURL: "https://discord.com/api/webhooks/123/token?thread_id=456&wait=true",
+			want:       "https://discord.com/api/webhooks/123/token/messages/1001?thread_id=456",
+		},
+		{
+			name:       "末尾のスラッシュ",
+			webhookURL: "https://discord.com/api/webhooks/123/token/",
+			want:       "https://discord.com/api/webhooks/123/token/messages/1001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageURL(tt.webhookURL, "1001"); got != tt.want {
+				t.Errorf("messageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWebhookID は、Webhook URLからのIDの取得をテストする
+func TestWebhookID(t *testing.T) {
+	tests := []struct {
+		webhookURL string
+		want       string
+	}{
+		{"https://discord.com/api/webhooks/123/token", "123"},
+		{"https://discord.com/api/v10/webhooks/123/token?thread_id=456", "123"},
+		{"https://discord.com/api/webhooks/123", ""},
+		{"https://example.com/hook", ""},
+	}
+
+	for _, tt := range tests {
+		if got := WebhookID(tt.webhookURL); got != tt.want {
+			t.Errorf("WebhookID(%q) = %q, want %q", tt.webhookURL, got, tt.want)
+		}
+	}
+}
+
+// TestEditMessage は、通知済みのメッセージの編集をテストする
+func TestEditMessage(t *testing.T) {
+	var received WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("method = %s, want PATCH", r.Method)
+		}
+		if r.URL.Path != "/api/webhooks/3003/token/messages/1001" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("thread_id") != "123" {
+			t.Errorf("thread_id = %q, want 123", r.URL.Query().Get("thread_id"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "1001", "channel_id": "2002", "webhook_id": "3003"}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token?thread_id=123", 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Security Advisory",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+		Category:    "Tech",
+		Mentions:    []string{"@here"},
+	}
+
+	if err := notifier.EditMessage(context.Background(), "1001", article); err != nil {
+		t.Fatalf("EditMessage() error = %v", err)
+	}
+
+	// メッセージ本文（メンション）は変更しない
+	if received.Content != "" {
+		t.Errorf("Content = %q, want empty", received.Content)
+	}
+	if len(received.Embeds) != 1 || received.Embeds[0].Title != "【更新】Security Advisory" {
+		t.Errorf("Embeds = %+v", received.Embeds)
+	}
+
+	if err := notifier.EditMessage(context.Background(), "", article); err == nil {
+		t.Error("EditMessage() with empty message ID should return error")
+	}
+}
+
+// TestEditDeletedMessage は、削除されたメッセージの編集をリトライしないことをテストする
+func TestEditDeletedMessage(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Unknown Message", "code": 10008}`))
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL+"/api/webhooks/3003/token", 0)
+	notifier.SetRetryDelay(0)
+
+	article := &models.Article{ID: "article-1", Title: "Security Advisory", PublishedAt: time.Now()}
+	if err := notifier.EditMessage(context.Background(), "1001", article); err == nil {
+		t.Error("EditMessage() for deleted message should return error")
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+// TestSendUpdate は、記事の更新の追加の通知をテストする
+func TestSendUpdate(t *testing.T) {
+	var received WebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, 0)
+	notifier.SetMaxRetries(1)
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Release Notes",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedName:    "Test Feed",
+		Category:    "Tech",
+		Mentions:    []string{"@here"},
+	}
+
+	if err := notifier.SendUpdate(context.Background(), article); err != nil {
+		t.Fatalf("SendUpdate() error = %v", err)
+	}
+
+	// 更新の通知ではメンションしない
+	if received.Content != updateNoticeContent {
+		t.Errorf("Content = %q, want %q", received.Content, updateNoticeContent)
+	}
+	if len(received.Embeds) != 1 || !strings.HasPrefix(received.Embeds[0].Title, updatedTitlePrefix) {
+		t.Errorf("Embeds = %+v", received.Embeds)
+	}
+}
@@ -0,0 +1,172 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+	"github.com/mmcdole/gofeed"
+)
+
+// maxResponseSize は、読み込むレスポンスボディの上限（10MB）
+const maxResponseSize = 10 << 20
+
+// ErrFeedNotFound は、HTMLページからフィードを自動検出できなかったことを示す
+var ErrFeedNotFound = errors.New("no feed link found in HTML page")
+
+// feedLinkPriorities は、自動検出の対象とする <link> の type 属性と優先度（小さいほど優先）
+var feedLinkPriorities = map[string]int{
+	"application/atom+xml":  0,
+	"application/rss+xml":   0,
+	"application/feed+json": 1,
+}
+
+// feedLink は、HTMLページから見つかったフィードの候補
+type feedLink struct {
+	// URL はフィードの絶対URL
+	URL string
+
+	// Title は <link> の title 属性
+	Title string
+
+	// priority は候補の優先度（小さいほど優先）
+	priority int
+}
+
+// parseResponse は、レスポンスボディをフィードとしてパースする
+// HTMLページの場合は <link rel="alternate"> からフィードを自動検出して取得し直す
+// 自動検出した場合は、検出したフィードURLを返す
+func (f *Fetcher) parseResponse(ctx context.Context, feedConfig *models.FeedConfig, resp *http.Response) (*gofeed.Feed, string, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// フィードとして認識できる場合、またはHTMLでない場合はそのままパース
+	if gofeed.DetectFeedType(bytes.NewReader(body)) != gofeed.FeedTypeUnknown ||
+		!isHTML(resp.Header.Get("Content-Type"), body) {
+		feed, err := f.parser.Parse(bytes.NewReader(body))
+		return feed, "", err
+	}
+
+	// HTMLページからフィードURLを探す
+	links, err := findFeedLinks(body, resp.Request.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(links) == 0 {
+		return nil, "", ErrFeedNotFound
+	}
+	discoveredURL := links[0].URL
+
+	// 検出したフィードを取得（自動検出時は条件付きGETを行わない）
+	discoveredResp, err := f.get(ctx, discoveredURL, feedConfig, models.CacheValidators{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch discovered feed %s: %w", discoveredURL, err)
+	}
+	defer discoveredResp.Body.Close()
+
+	feed, err := f.parser.Parse(io.LimitReader(discoveredResp.Body, maxResponseSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse discovered feed %s: %w", discoveredURL, err)
+	}
+
+	return feed, discoveredURL, nil
+}
+
+// isHTML は、レスポンスがHTMLページかどうかを判定する
+func isHTML(contentType string, body []byte) bool {
+	if strings.Contains(strings.ToLower(contentType), "html") {
+		return true
+	}
+
+	head := body
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.ToLower(head)
+	return bytes.Contains(head, []byte("<!doctype html")) || bytes.Contains(head, []byte("<html"))
+}
+
+// findFeedLinks は、HTMLから <link rel="alternate"> のフィードURLを優先度順に抽出する
+// 相対URLは <base href> またはページのURLを基準に解決する
+func findFeedLinks(body []byte, pageURL *url.URL) ([]feedLink, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	baseURL := pageURL
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+			baseURL = u
+		}
+	}
+
+	links := make([]feedLink, 0)
+	seen := make(map[string]bool)
+
+	doc.Find("link[rel][href]").Each(func(_ int, s *goquery.Selection) {
+		rel, _ := s.Attr("rel")
+		if !hasToken(rel, "alternate") {
+			return
+		}
+
+		linkType, _ := s.Attr("type")
+		linkType = strings.ToLower(strings.TrimSpace(strings.SplitN(linkType, ";", 2)[0]))
+		priority, ok := feedLinkPriorities[linkType]
+		if !ok {
+			return
+		}
+
+		href, _ := s.Attr("href")
+		u, err := baseURL.Parse(strings.TrimSpace(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || seen[u.String()] {
+			return
+		}
+		seen[u.String()] = true
+
+		// コメントフィードはメインのフィードより優先度を下げる
+		title, _ := s.Attr("title")
+		if isCommentFeed(title, u.String()) {
+			priority += 10
+		}
+
+		links = append(links, feedLink{
+			URL:      u.String(),
+			Title:    title,
+			priority: priority,
+		})
+	})
+
+	// 優先度順（同じ優先度の場合はページ内の出現順）
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].priority < links[j].priority
+	})
+
+	return links, nil
+}
+
+// hasToken は、空白区切りの属性値に指定されたトークンが含まれるかチェックする
+func hasToken(value, token string) bool {
+	for _, v := range strings.Fields(value) {
+		if strings.EqualFold(v, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCommentFeed は、コメント用のフィードかどうかを判定する
+func isCommentFeed(title, feedURL string) bool {
+	s := strings.ToLower(title + " " + feedURL)
+	return strings.Contains(s, "comment") || strings.Contains(s, "コメント")
+}
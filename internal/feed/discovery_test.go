@@ -0,0 +1,174 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestFindFeedLinks は、HTMLからのフィードURL抽出をテストする
+func TestFindFeedLinks(t *testing.T) {
+	pageURL, _ := url.Parse("https://example.com/blog/")
+
+	tests := []struct {
+		name string
+		html string
+		want []string
+	}{
+		{
+			name: "RSSとAtom",
+			html: `<html><head>
+				<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+				<link rel="alternate" type="application/atom+xml" href="atom.xml">
+			</head></html>`,
+			want: []string{"https://example.com/feed.xml", "https://example.com/blog/atom.xml"},
+		},
+		{
+			name: "コメントフィードとJSON Feedは後回し",
+			html: `<html><head>
+				<link rel="alternate" type="application/rss+xml" title="Comments Feed" href="/comments/feed">
+				<link rel="alternate" type="application/feed+json" href="/feed.json">
+				<link rel="alternate" type="application/rss+xml" title="Blog" href="/feed">
+			</head></html>`,
+			want: []string{"https://example.com/feed", "https://example.com/feed.json", "https://example.com/comments/feed"},
+		},
+		{
+			name: "base要素とrelの複数トークン",
+			html: `<html><head>
+				<base href="https://cdn.example.com/">
+				<link rel="Alternate feed" type="application/atom+xml; charset=utf-8" href="atom.xml">
+			</head></html>`,
+			want: []string{"https://cdn.example.com/atom.xml"},
+		},
+		{
+			name: "フィード以外のlink",
+			html: `<html><head>
+				<link rel="stylesheet" href="/style.css">
+				<link rel="alternate" hreflang="en" href="/en/">
+			</head></html>`,
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := findFeedLinks([]byte(tt.html), pageURL)
+			if err != nil {
+				t.Fatalf("findFeedLinks() error = %v", err)
+			}
+			if len(links) != len(tt.want) {
+				t.Fatalf("findFeedLinks() returned %d links, want %d: %+v", len(links), len(tt.want), links)
+			}
+			for i, want := range tt.want {
+				if links[i].URL != want {
+					t.Errorf("links[%d] = %v, want %v", i, links[i].URL, want)
+				}
+			}
+		})
+	}
+}
+
+// TestFetchDiscovery は、WebサイトのURLからのフィード自動検出をテストする
+func TestFetchDiscovery(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html><html><head>
+			<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+		</head><body>Blog</body></html>`))
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	})
+	mux.HandleFunc("/nofeed", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>No feed</body></html>`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetRetryPolicy(0, 0)
+	ctx := context.Background()
+
+	// ホームページのURLでも記事を取得できる
+	articles, err := fetcher.Fetch(ctx, &models.FeedConfig{Name: "Blog", URL: server.URL + "/", Enabled: true})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("articles length = %d, want 1", len(articles))
+	}
+
+	// GetFeedInfo も同じ自動検出を使用する
+	title, _, err := fetcher.GetFeedInfo(ctx, server.URL+"/")
+	if err != nil {
+		t.Fatalf("GetFeedInfo() error = %v", err)
+	}
+	if title != "Test Feed" {
+		t.Errorf("title = %v, want Test Feed", title)
+	}
+
+	// フィードが見つからない場合はエラー
+	_, err = fetcher.Fetch(ctx, &models.FeedConfig{Name: "No Feed", URL: server.URL + "/nofeed", Enabled: true})
+	if !errors.Is(err, ErrFeedNotFound) {
+		t.Errorf("Fetch() error = %v, want ErrFeedNotFound", err)
+	}
+}
+
+// TestFetchDiscoveryOtherHost は、別のホストのフィードを自動検出した場合に認証情報を送らないことをテストする
+func TestFetchDiscoveryOtherHost(t *testing.T) {
+	var feedHeader http.Header
+	feedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feedHeader = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	}))
+	defer feedServer.Close()
+
+	var pageHeader http.Header
+	pageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pageHeader = r.Header.Clone()
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html><html><head>
+			<link rel="alternate" type="application/rss+xml" href="` + feedServer.URL + `/feed.xml">
+		</head><body>Blog</body></html>`))
+	}))
+	defer pageServer.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetRetryPolicy(0, 0)
+
+	articles, err := fetcher.Fetch(context.Background(), &models.FeedConfig{
+		Name:    "Private Blog",
+		URL:     pageServer.URL + "/",
+		Auth:    &models.FeedAuth{BearerToken: "secret", Cookie: "session=abc"},
+		Headers: map[string]string{"X-Api-Key": "api-key"},
+		Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("articles length = %d, want 1", len(articles))
+	}
+
+	// フィードURLと同じホストには認証情報を送る
+	if pageHeader.Get("Authorization") != "Bearer secret" || pageHeader.Get("X-Api-Key") != "api-key" {
+		t.Errorf("page request headers = %v, want credentials", pageHeader)
+	}
+
+	// 別のホストには送らない
+	for _, name := range []string{"Authorization", "Cookie", "X-Api-Key"} {
+		if v := feedHeader.Get(name); v != "" {
+			t.Errorf("discovered feed request header %s = %q, want empty", name, v)
+		}
+	}
+}
@@ -2,40 +2,186 @@ package feed
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"net/http"
 	"sync"
 	"time"
 
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
+	"github.com/ken344/rss-discord-notifier/internal/httpclient"
 	"github.com/ken344/rss-discord-notifier/internal/logger"
 	"github.com/ken344/rss-discord-notifier/pkg/models"
 	"github.com/mmcdole/gofeed"
 )
 
+// ErrNotModified は、フィードが前回の取得から更新されていないことを示す（304 Not Modified）
+var ErrNotModified = errors.New("feed not modified")
+
 // Fetcher は、RSSフィードを取得する構造体
 type Fetcher struct {
 	// parser はRSSパーサー
 	parser *gofeed.Parser
 
+	// transport はフィード取得に使用するHTTPトランスポート（Discord通知と共有可能）
+	transport *httpclient.Transport
+
 	// timeout はフィード取得のタイムアウト
 	timeout time.Duration
+
+	// userAgent はフィード設定で指定がない場合に使用するUser-Agent
+	userAgent string
+
+	// maxConcurrency は全体の同時取得数の上限
+	maxConcurrency int
+
+	// maxConcurrencyPerHost は同一ホストへの同時取得数の上限
+	maxConcurrencyPerHost int
+
+	// maxRetries はフィード取得の最大リトライ回数
+	maxRetries int
+
+	// retryBaseDelay はリトライ間隔の初期値（指数バックオフの基準）
+	retryBaseDelay time.Duration
+
+	// retryMaxDelay はリトライ間隔の上限
+	retryMaxDelay time.Duration
+
+	// validators はフィードURLごとの条件付きGET用の検証子（ETag / Last-Modified）
+	validators map[string]models.CacheValidators
+
+	// mu はvalidatorsへの並行アクセスを保護する
+	mu sync.Mutex
+}
+
+// FetchResult は、複数フィードの取得結果を表す
+type FetchResult struct {
+	// Articles は取得したすべての記事
+	Articles []*models.Article
+
+	// SuccessCount は取得に成功したフィード数（未更新のフィードを含む）
+	SuccessCount int
+
+	// FailedCount は取得に失敗したフィード数
+	FailedCount int
+
+	// NotModifiedCount は304 Not Modifiedが返されたフィード数
+	NotModifiedCount int
+
+	// FetchedFeeds は内容を取得できたフィードのURL（未更新・取得に失敗したフィードは含まない）
+	// 記事がフィードから削除されたかの判定に使用する
+	FetchedFeeds map[string]bool
 }
 
 // NewFetcher は、新しいフィード取得器を作成する
 func NewFetcher(timeout time.Duration) *Fetcher {
 	return &Fetcher{
-		parser:  gofeed.NewParser(),
-		timeout: timeout,
+		parser:                gofeed.NewParser(),
+		transport:             httpclient.NewDefaultTransport(),
+		timeout:               timeout,
+		userAgent:             DefaultUserAgent,
+		maxConcurrency:        10,
+		maxConcurrencyPerHost: 2,
+		maxRetries:            2,
+		retryBaseDelay:        1 * time.Second,
+		retryMaxDelay:         30 * time.Second,
+		validators:            make(map[string]models.CacheValidators),
+	}
+}
+
+// SetTransport は、フィード取得に使用するHTTPトランスポートを設定する
+// Discord通知と同じトランスポートを渡すことで、コネクションプールを共有できる
+func (f *Fetcher) SetTransport(transport *httpclient.Transport) {
+	if transport != nil {
+		f.transport = transport
+	}
+}
+
+// SetUserAgent は、デフォルトのUser-Agentを設定する
+func (f *Fetcher) SetUserAgent(userAgent string) {
+	if userAgent != "" {
+		f.userAgent = userAgent
+	}
+}
+
+// SetRetryPolicy は、フィード取得の最大リトライ回数とリトライ間隔の上限を設定する
+// 負のリトライ回数と0以下の間隔は無視される
+func (f *Fetcher) SetRetryPolicy(maxRetries int, maxDelay time.Duration) {
+	if maxRetries >= 0 {
+		f.maxRetries = maxRetries
+	}
+	if maxDelay > 0 {
+		f.retryMaxDelay = maxDelay
+	}
+}
+
+// SetRetryBaseDelay は、リトライ間隔の初期値を設定する
+func (f *Fetcher) SetRetryBaseDelay(duration time.Duration) {
+	if duration > 0 {
+		f.retryBaseDelay = duration
 	}
 }
 
+// SetConcurrency は、全体とホストごとの同時取得数の上限を設定する
+// 0以下の値は無視される
+func (f *Fetcher) SetConcurrency(maxConcurrency, maxConcurrencyPerHost int) {
+	if maxConcurrency > 0 {
+		f.maxConcurrency = maxConcurrency
+	}
+	if maxConcurrencyPerHost > 0 {
+		f.maxConcurrencyPerHost = maxConcurrencyPerHost
+	}
+}
+
+// SetCacheValidators は、前回実行時に保存した条件付きGET用の検証子を設定する
+func (f *Fetcher) SetCacheValidators(validators map[string]models.CacheValidators) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.validators = make(map[string]models.CacheValidators, len(validators))
+	for feedURL, v := range validators {
+		f.validators[feedURL] = v
+	}
+}
+
+// CacheValidators は、現在の条件付きGET用の検証子を返す
+// 今回の取得でサーバーから返された値が反映されている
+func (f *Fetcher) CacheValidators() map[string]models.CacheValidators {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	validators := make(map[string]models.CacheValidators, len(f.validators))
+	for feedURL, v := range f.validators {
+		validators[feedURL] = v
+	}
+	return validators
+}
+
+// getValidators は、指定されたフィードの検証子を返す
+func (f *Fetcher) getValidators(feedURL string) models.CacheValidators {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.validators[feedURL]
+}
+
+// setValidators は、指定されたフィードの検証子を更新する
+func (f *Fetcher) setValidators(feedURL string, v models.CacheValidators) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validators[feedURL] = v
+}
+
 // FetchAll は、複数のフィードから記事を並行で取得する
-func (f *Fetcher) FetchAll(ctx context.Context, feedConfigs []*models.FeedConfig) ([]*models.Article, error) {
+// 同時取得数は全体とホストごとの上限で制限され、フィードはホスト間で公平に払い出される
+func (f *Fetcher) FetchAll(ctx context.Context, feedConfigs []*models.FeedConfig) (*FetchResult, error) {
 	if len(feedConfigs) == 0 {
-		return []*models.Article{}, nil
+		return &FetchResult{Articles: []*models.Article{}, FetchedFeeds: map[string]bool{}}, nil
 	}
 
-	logger.Info("RSSフィードの取得を開始", "feed_count", len(feedConfigs))
+	logger.Info("RSSフィードの取得を開始",
+		"feed_count", len(feedConfigs),
+		"max_concurrency", f.maxConcurrency,
+		"max_concurrency_per_host", f.maxConcurrencyPerHost)
 	startTime := time.Now()
 
 	// 結果を格納するチャネル
@@ -43,23 +189,41 @@ func (f *Fetcher) FetchAll(ctx context.Context, feedConfigs []*models.FeedConfig
 		articles []*models.Article
 		err      error
 		feedName string
+		feedURL  string
 	}
 	resultChan := make(chan result, len(feedConfigs))
 
-	// 各フィードを並行で取得
+	// ワーカー数は全体の上限とフィード数の小さい方
+	workers := f.maxConcurrency
+	if workers > len(feedConfigs) {
+		workers = len(feedConfigs)
+	}
+
+	// 各フィードをワーカープールで並行取得
+	scheduler := newHostScheduler(feedConfigs, f.maxConcurrencyPerHost)
 	var wg sync.WaitGroup
-	for _, feedConfig := range feedConfigs {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go func(fc *models.FeedConfig) {
+		go func() {
 			defer wg.Done()
 
-			articles, err := f.Fetch(ctx, fc)
-			resultChan <- result{
-				articles: articles,
-				err:      err,
-				feedName: fc.Name,
+			for {
+				fc, host := scheduler.acquire()
+				if fc == nil {
+					return
+				}
+
+				articles, err := f.Fetch(ctx, fc)
+				scheduler.release(host)
+
+				resultChan <- result{
+					articles: articles,
+					err:      err,
+					feedName: fc.Name,
+					feedURL:  fc.URL,
+				}
 			}
-		}(feedConfig)
+		}()
 	}
 
 	// すべてのgoroutineが終了するのを待つ
@@ -69,21 +233,31 @@ func (f *Fetcher) FetchAll(ctx context.Context, feedConfigs []*models.FeedConfig
 	}()
 
 	// 結果を集約
-	var allArticles []*models.Article
-	successCount := 0
-	errorCount := 0
+	fetchResult := &FetchResult{
+		Articles:     []*models.Article{},
+		FetchedFeeds: make(map[string]bool),
+	}
 
 	for res := range resultChan {
+		if errors.Is(res.err, ErrNotModified) {
+			logger.Info("フィードは更新されていません（304 Not Modified）",
+				"feed_name", res.feedName)
+			fetchResult.NotModifiedCount++
+			fetchResult.SuccessCount++
+			continue
+		}
+
 		if res.err != nil {
 			logger.Warn("フィードの取得に失敗",
 				"feed_name", res.feedName,
 				"error", res.err)
-			errorCount++
+			fetchResult.FailedCount++
 			continue
 		}
 
-		allArticles = append(allArticles, res.articles...)
-		successCount++
+		fetchResult.Articles = append(fetchResult.Articles, res.articles...)
+		fetchResult.FetchedFeeds[res.feedURL] = true
+		fetchResult.SuccessCount++
 		logger.Debug("フィードを取得",
 			"feed_name", res.feedName,
 			"article_count", len(res.articles))
@@ -92,16 +266,65 @@ func (f *Fetcher) FetchAll(ctx context.Context, feedConfigs []*models.FeedConfig
 	duration := time.Since(startTime)
 	logger.Info("RSSフィードの取得が完了",
 		"total_feeds", len(feedConfigs),
-		"success", successCount,
-		"failed", errorCount,
-		"total_articles", len(allArticles),
+		"success", fetchResult.SuccessCount,
+		"failed", fetchResult.FailedCount,
+		"not_modified", fetchResult.NotModifiedCount,
+		"total_articles", len(fetchResult.Articles),
 		"duration_seconds", duration.Seconds())
 
-	return allArticles, nil
+	return fetchResult, nil
 }
 
 // Fetch は、単一のフィードから記事を取得する
+// 一時的なエラーの場合は指数バックオフでリトライする
+// フィードが前回から更新されていない場合は ErrNotModified を返す
 func (f *Fetcher) Fetch(ctx context.Context, feedConfig *models.FeedConfig) ([]*models.Article, error) {
+	maxRetries, maxDelay := f.retryPolicy(feedConfig)
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		articles, err := f.fetchOnce(ctx, feedConfig)
+		if err == nil || errors.Is(err, ErrNotModified) {
+			return articles, err
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !isRetryable(err) || attempt == maxRetries {
+			break
+		}
+
+		// 待機時間の決定（Retry-After > 指数バックオフ）
+		delay := backoffDelay(attempt, f.retryBaseDelay, maxDelay)
+		if retryAfter := retryAfterOf(err); retryAfter > 0 {
+			if retryAfter > maxDelay {
+				logger.Warn("Retry-Afterが上限を超えるためリトライを中止",
+					"feed_name", feedConfig.Name,
+					"retry_after_seconds", retryAfter.Seconds(),
+					"max_delay_seconds", maxDelay.Seconds())
+				break
+			}
+			delay = retryAfter
+		}
+
+		logger.Warn("フィードの取得に失敗したためリトライします",
+			"feed_name", feedConfig.Name,
+			"attempt", attempt+1,
+			"max_retries", maxRetries,
+			"delay_seconds", delay.Seconds(),
+			"error", err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
+// fetchOnce は、単一のフィードを1回だけ取得する
+func (f *Fetcher) fetchOnce(ctx context.Context, feedConfig *models.FeedConfig) ([]*models.Article, error) {
 	// タイムアウト付きコンテキストを作成
 	fetchCtx, cancel := context.WithTimeout(ctx, f.timeout)
 	defer cancel()
@@ -110,10 +333,46 @@ func (f *Fetcher) Fetch(ctx context.Context, feedConfig *models.FeedConfig) ([]*
 		"feed_name", feedConfig.Name,
 		"feed_url", feedConfig.URL)
 
-	// RSSフィードを取得
-	feed, err := f.parser.ParseURLWithContext(feedConfig.URL, fetchCtx)
+	// RSSフィードを取得（条件付きGET）
+	resp, err := f.get(fetchCtx, feedConfig.URL, feedConfig, f.getValidators(feedConfig.URL))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse feed %s: %w", feedConfig.Name, err)
+		return nil, fmt.Errorf("failed to fetch feed %s: %w", feedConfig.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, ErrNotModified
+	}
+
+	var feed *gofeed.Feed
+	var discoveredURL string
+	if feedConfig.IsHTML() {
+		// フィードのないWebページはCSSセレクターで記事を抽出
+		feed, err = f.scrapeResponse(resp, feedConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrape page %s: %w", feedConfig.Name, err)
+		}
+	} else {
+		feed, discoveredURL, err = f.parseResponse(fetchCtx, feedConfig, resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse feed %s: %w", feedConfig.Name, err)
+		}
+	}
+
+	if discoveredURL != "" {
+		// HTMLページから自動検出した場合は、設定の更新を促す
+		// 検証子はHTMLページのものになるため記録しない
+		logger.Warn("HTMLページからフィードを自動検出しました。設定のURLを更新してください",
+			"feed_name", feedConfig.Name,
+			"configured_url", feedConfig.URL,
+			"discovered_url", discoveredURL)
+		f.setValidators(feedConfig.URL, models.CacheValidators{})
+	} else {
+		// 次回の条件付きGETのために検証子を記録
+		f.setValidators(feedConfig.URL, models.CacheValidators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
 	}
 
 	// フィードから記事を抽出
@@ -128,6 +387,55 @@ func (f *Fetcher) Fetch(ctx context.Context, feedConfig *models.FeedConfig) ([]*
 	return articles, nil
 }
 
+// get は、フィードURLにGETリクエストを送信する
+// フィード設定のUser-Agent・ヘッダー・認証情報を付与し、
+// 検証子が指定されている場合は If-None-Match / If-Modified-Since を付与する
+// 2xx と 304 以外のステータスはエラーとして扱う
+func (f *Fetcher) get(ctx context.Context, feedURL string, feedConfig *models.FeedConfig, validators models.CacheValidators) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme: %q", feedURL)
+	}
+
+	setRequestHeaders(req, feedConfig, f.userAgent)
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	// タイムアウトはコンテキストで制御するため、クライアント側では設定しない
+	client := f.transport.Client(0)
+	if feedConfig.InsecureSkipVerify {
+		client = f.transport.InsecureClient(0)
+	}
+	client.CheckRedirect = redirectPolicy(feedConfig, f.userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	return resp, nil
+}
+
 // convertToArticle は、gofeed.Itemをmodels.Articleに変換する
 func (f *Fetcher) convertToArticle(item *gofeed.Item, feedConfig *models.FeedConfig, feed *gofeed.Feed) *models.Article {
 	// IDの決定（GUID > Link）
@@ -154,8 +462,13 @@ func (f *Fetcher) convertToArticle(item *gofeed.Item, feedConfig *models.FeedCon
 		description = item.Content
 	}
 
-	// HTMLタグを削除（簡易版）
-	description = f.stripHTML(description)
+	// HTMLをテキストに変換（フィード設定で指定された場合はMarkdownに変換）
+	descriptionIsMarkdown := feedConfig.DescriptionFormat == models.DescriptionFormatMarkdown
+	if descriptionIsMarkdown {
+		description = htmlconv.ToMarkdown(description, url)
+	} else {
+		description = f.stripHTML(description)
+	}
 
 	// 本文
 	content := item.Content
@@ -189,7 +502,7 @@ func (f *Fetcher) convertToArticle(item *gofeed.Item, feedConfig *models.FeedCon
 	}
 
 	// 画像URL の取得
-	imageURL := f.extractImageURL(item)
+	imageURL := f.extractImageURL(item, url)
 
 	return &models.Article{
 		ID:          id,
@@ -203,73 +516,43 @@ func (f *Fetcher) convertToArticle(item *gofeed.Item, feedConfig *models.FeedCon
 		FeedName:    feedConfig.Name,
 		FeedURL:     feedConfig.URL,
 		Category:    feedConfig.Category,
+		Tags:        item.Categories,
 		WebhookURL:  feedConfig.WebhookURL, // フィード設定のWebhook URLを引き継ぐ
 		ImageURL:    imageURL,              // 記事の画像URL
-	}
-}
 
-// extractImageURL はRSSアイテムから画像URLを抽出する
-func (f *Fetcher) extractImageURL(item *gofeed.Item) string {
-	// 1. Item.Image フィールドから取得
-	if item.Image != nil && item.Image.URL != "" {
-		return item.Image.URL
-	}
-
-	// 2. Enclosures から画像を探す
-	for _, enc := range item.Enclosures {
-		if enc.Type != "" && strings.HasPrefix(enc.Type, "image/") {
-			return enc.URL
-		}
+		DescriptionIsMarkdown: descriptionIsMarkdown,
 	}
-
-	// 3. 画像が見つからない場合は空文字列
-	return ""
 }
 
-// stripHTML は、HTMLタグを簡易的に削除する
+// stripHTML は、HTMLをプレーンテキストに変換する
+// 文字参照をデコードし、script / style などを除去して段落の区切りを残す
 func (f *Fetcher) stripHTML(s string) string {
-	// 簡易的なHTMLタグ除去
-	// より高度な処理が必要な場合は、goquery等を使用
-	s = strings.ReplaceAll(s, "<br>", " ")
-	s = strings.ReplaceAll(s, "<br/>", " ")
-	s = strings.ReplaceAll(s, "<br />", " ")
-	s = strings.ReplaceAll(s, "</p>", " ")
-
-	// タグを削除（簡易版）
-	// タグの開始時にスペースを追加して、単語がくっつくのを防ぐ
-	inTag := false
-	var result strings.Builder
-	for _, char := range s {
-		if char == '<' {
-			inTag = true
-			result.WriteRune(' ') // タグの前にスペースを追加
-			continue
-		}
-		if char == '>' {
-			inTag = false
-			continue
-		}
-		if !inTag {
-			result.WriteRune(char)
-		}
-	}
-
-	// 連続する空白を1つにまとめる
-	text := result.String()
-	text = strings.Join(strings.Fields(text), " ")
-
-	return strings.TrimSpace(text)
+	return htmlconv.ToText(s)
 }
 
 // GetFeedInfo は、フィードの情報を取得する（記事は取得しない）
+// WebサイトのURLが指定された場合は、フィードを自動検出する
 func (f *Fetcher) GetFeedInfo(ctx context.Context, feedURL string) (title string, description string, err error) {
 	fetchCtx, cancel := context.WithTimeout(ctx, f.timeout)
 	defer cancel()
 
-	feed, err := f.parser.ParseURLWithContext(feedURL, fetchCtx)
+	feedConfig := &models.FeedConfig{URL: feedURL}
+	resp, err := f.get(fetchCtx, feedURL, feedConfig, models.CacheValidators{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	feed, discoveredURL, err := f.parseResponse(fetchCtx, feedConfig, resp)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to parse feed: %w", err)
 	}
 
+	if discoveredURL != "" {
+		logger.Info("HTMLページからフィードを自動検出しました",
+			"url", feedURL,
+			"discovered_url", discoveredURL)
+	}
+
 	return feed.Title, feed.Description, nil
 }
@@ -2,7 +2,11 @@ package feed
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -159,17 +163,32 @@ func TestStripHTML(t *testing.T) {
 		{
 			name:  "改行タグ",
 			input: "Line 1<br>Line 2<br/>Line 3",
-			want:  "Line 1 Line 2 Line 3",
+			want:  "Line 1\nLine 2\nLine 3",
 		},
 		{
 			name:  "段落タグ",
 			input: "<p>Paragraph 1</p><p>Paragraph 2</p>",
-			want:  "Paragraph 1 Paragraph 2",
+			want:  "Paragraph 1\n\nParagraph 2",
 		},
 		{
 			name:  "複雑なHTML",
 			input: "<div><h1>Title</h1><p>Content with <a href='#'>link</a></p></div>",
-			want:  "Title Content with link",
+			want:  "Title\n\nContent with link",
+		},
+		{
+			name:  "文字参照",
+			input: "Tom &amp; Jerry&#39;s&nbsp;show &lt;3",
+			want:  "Tom & Jerry's show <3",
+		},
+		{
+			name:  "属性値内の >",
+			input: `<a href="#" title="a > b">link</a> text`,
+			want:  "link text",
+		},
+		{
+			name:  "scriptとstyleの中身を除去",
+			input: "<style>p { color: red; }</style><p>Body</p><script>alert('x > y');</script>",
+			want:  "Body",
 		},
 		{
 			name:  "空文字列",
@@ -224,15 +243,15 @@ func TestFetchAll(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			articles, err := fetcher.FetchAll(ctx, tt.feedConfigs)
+			result, err := fetcher.FetchAll(ctx, tt.feedConfigs)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("FetchAll() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
 			// 空のフィードリストの場合、記事も空
-			if len(tt.feedConfigs) == 0 && len(articles) != 0 {
-				t.Errorf("FetchAll() with empty config should return empty articles, got %d", len(articles))
+			if len(tt.feedConfigs) == 0 && len(result.Articles) != 0 {
+				t.Errorf("FetchAll() with empty config should return empty articles, got %d", len(result.Articles))
 			}
 		})
 	}
@@ -241,6 +260,7 @@ func TestFetchAll(t *testing.T) {
 // TestFetch は、単一フィードの取得をテストする
 func TestFetch(t *testing.T) {
 	fetcher := NewFetcher(30 * time.Second)
+	fetcher.SetRetryPolicy(0, 0) // ネットワークエラーのリトライを無効化
 	ctx := context.Background()
 
 	tests := []struct {
@@ -284,6 +304,7 @@ func TestFetch(t *testing.T) {
 func TestFetchWithTimeout(t *testing.T) {
 	// 極端に短いタイムアウトを設定
 	fetcher := NewFetcher(1 * time.Nanosecond)
+	fetcher.SetRetryPolicy(0, 0)
 	ctx := context.Background()
 
 	feedConfig := &models.FeedConfig{
@@ -299,3 +320,171 @@ func TestFetchWithTimeout(t *testing.T) {
 		t.Error("Fetch() with very short timeout should return error")
 	}
 }
+
+// testRSS は、テスト用のRSSフィード
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Test Feed</title>
+    <link>https://example.com/</link>
+    <description>Test Feed Description</description>
+    <item>
+      <guid>article-1</guid>
+      <title>Test Article</title>
+      <link>https://example.com/article-1</link>
+      <description>Test Description</description>
+    </item>
+  </channel>
+</rss>`
+
+// TestFetchConditionalGet は、ETag / Last-Modified による条件付きGETをテストする
+func TestFetchConditionalGet(t *testing.T) {
+	const etag = `"v1"`
+	const lastModified = "Wed, 12 Nov 2025 10:00:00 GMT"
+
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	ctx := context.Background()
+	feedConfig := &models.FeedConfig{
+		Name:     "Test Feed",
+		URL:      server.URL,
+		Category: "Tech",
+		Enabled:  true,
+	}
+
+	// 1回目: 全件取得され、検証子が記録される
+	articles, err := fetcher.Fetch(ctx, feedConfig)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("articles length = %d, want 1", len(articles))
+	}
+
+	validators := fetcher.CacheValidators()[server.URL]
+	if validators.ETag != etag || validators.LastModified != lastModified {
+		t.Errorf("CacheValidators() = %+v, want ETag=%s LastModified=%s", validators, etag, lastModified)
+	}
+
+	// 2回目: 検証子を引き継いだ新しい取得器で304が返される
+	fetcher2 := NewFetcher(5 * time.Second)
+	fetcher2.SetCacheValidators(fetcher.CacheValidators())
+
+	_, err = fetcher2.Fetch(ctx, feedConfig)
+	if !errors.Is(err, ErrNotModified) {
+		t.Errorf("Fetch() error = %v, want ErrNotModified", err)
+	}
+
+	result, err := fetcher2.FetchAll(ctx, []*models.FeedConfig{feedConfig})
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+	if result.NotModifiedCount != 1 || result.FailedCount != 0 || len(result.Articles) != 0 {
+		t.Errorf("FetchAll() = %+v, want 1 not modified feed and no articles", result)
+	}
+	if result.FetchedFeeds[server.URL] {
+		t.Error("FetchedFeeds should not include a not modified feed")
+	}
+
+	if requestCount != 3 {
+		t.Errorf("request count = %d, want 3", requestCount)
+	}
+}
+
+// TestFetchAllConcurrencyLimit は、ホストごとの同時取得数の上限をテストする
+func TestFetchAllConcurrencyLimit(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetConcurrency(10, 2)
+
+	feedConfigs := make([]*models.FeedConfig, 0)
+	for i := 0; i < 8; i++ {
+		feedConfigs = append(feedConfigs, &models.FeedConfig{
+			Name:     "Test Feed",
+			URL:      server.URL + "/feed/" + string(rune('a'+i)),
+			Category: "Tech",
+			Enabled:  true,
+		})
+	}
+
+	result, err := fetcher.FetchAll(context.Background(), feedConfigs)
+	if err != nil {
+		t.Fatalf("FetchAll() error = %v", err)
+	}
+
+	if result.SuccessCount != len(feedConfigs) {
+		t.Errorf("SuccessCount = %d, want %d", result.SuccessCount, len(feedConfigs))
+	}
+
+	if maxInFlight > 2 {
+		t.Errorf("max in-flight requests = %d, want <= 2", maxInFlight)
+	}
+}
+
+// TestConvertToArticleMarkdown は、説明文のMarkdown変換（オプトイン）をテストする
+func TestConvertToArticleMarkdown(t *testing.T) {
+	fetcher := NewFetcher(30 * time.Second)
+	item := &gofeed.Item{
+		GUID:        "article-1",
+		Title:       "Test Article",
+		Link:        "https://example.com/posts/1",
+		Description: `<p>See <a href="/docs">the <strong>docs</strong></a> for snake_case.</p>`,
+	}
+
+	tests := []struct {
+		name         string
+		format       string
+		want         string
+		wantMarkdown bool
+	}{
+		{"デフォルトはテキスト", "", "See the docs for snake_case.", false},
+		{"Markdown", models.DescriptionFormatMarkdown, `See [the **docs**](https://example.com/docs) for snake\_case.`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedConfig := &models.FeedConfig{Name: "Test Feed", URL: "https://example.com/feed", DescriptionFormat: tt.format}
+			got := fetcher.convertToArticle(item, feedConfig, &gofeed.Feed{})
+
+			if got.Description != tt.want {
+				t.Errorf("Description = %q, want %q", got.Description, tt.want)
+			}
+			if got.DescriptionIsMarkdown != tt.wantMarkdown {
+				t.Errorf("DescriptionIsMarkdown = %v, want %v", got.DescriptionIsMarkdown, tt.wantMarkdown)
+			}
+		})
+	}
+}
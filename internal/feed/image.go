@@ -0,0 +1,286 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ken344/rss-discord-notifier/internal/htmlconv"
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"golang.org/x/net/html"
+)
+
+// pageImageSelectors は、記事ページから画像URLを探すメタタグ（優先順）
+var pageImageSelectors = []string{
+	`meta[property="og:image"]`,
+	`meta[property="og:image:url"]`,
+	`meta[property="og:image:secure_url"]`,
+	`meta[name="twitter:image"]`,
+	`meta[name="twitter:image:src"]`,
+	`meta[property="twitter:image"]`,
+}
+
+// extractImageURL はRSSアイテムから画像URLを抽出する
+// 相対URLは記事のURLを基準に解決する
+func (f *Fetcher) extractImageURL(item *gofeed.Item, articleURL string) string {
+	candidates := []func() string{
+		// 1. Item.Image フィールド
+		func() string {
+			if item.Image != nil {
+				return item.Image.URL
+			}
+			return ""
+		},
+		// 2. Media RSS（media:thumbnail / media:content / media:group）
+		func() string { return mediaImageURL(item.Extensions) },
+		// 3. 画像のEnclosure
+		func() string {
+			for _, enc := range item.Enclosures {
+				if strings.HasPrefix(enc.Type, "image/") {
+					return enc.URL
+				}
+			}
+			return ""
+		},
+		// 4. iTunes の画像（ポッドキャスト）
+		func() string {
+			if item.ITunesExt != nil {
+				return item.ITunesExt.Image
+			}
+			return ""
+		},
+		// 5. 本文・説明文の最初の <img>
+		func() string { return firstImgSrc(item.Content) },
+		func() string { return firstImgSrc(item.Description) },
+	}
+
+	for _, candidate := range candidates {
+		if imageURL := resolveImageURL(candidate(), articleURL); imageURL != "" {
+			return imageURL
+		}
+	}
+
+	// 画像が見つからない場合は空文字列
+	return ""
+}
+
+// mediaImageURL は、Media RSS の拡張要素から画像URLを探す
+func mediaImageURL(extensions ext.Extensions) string {
+	media, ok := extensions["media"]
+	if !ok {
+		return ""
+	}
+	return mediaImageURLFrom(media)
+}
+
+// mediaImageURLFrom は、media名前空間の要素から画像URLを探す（media:group の中も探す）
+func mediaImageURLFrom(media map[string][]ext.Extension) string {
+	for _, thumbnail := range media["thumbnail"] {
+		if u := thumbnail.Attrs["url"]; u != "" {
+			return u
+		}
+	}
+
+	for _, content := range media["content"] {
+		if content.Attrs["medium"] == "image" || strings.HasPrefix(content.Attrs["type"], "image/") {
+			if u := content.Attrs["url"]; u != "" {
+				return u
+			}
+		}
+		// media:content の子要素の media:thumbnail
+		if u := mediaImageURLFrom(content.Children); u != "" {
+			return u
+		}
+	}
+
+	for _, group := range media["group"] {
+		if u := mediaImageURLFrom(group.Children); u != "" {
+			return u
+		}
+	}
+
+	return ""
+}
+
+// firstImgSrc は、HTML内の最初の <img> の画像URLを返す
+// 遅延読み込み用の data-src と、1x1 のトラッキング画像にも対応する
+func firstImgSrc(s string) string {
+	if !strings.Contains(s, "<img") && !strings.Contains(s, "<IMG") {
+		return ""
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return ""
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+
+		token := z.Token()
+		if token.Data != "img" {
+			continue
+		}
+
+		src := htmlconv.Attr(token, "src")
+		if dataSrc := htmlconv.Attr(token, "data-src"); dataSrc != "" && (src == "" || strings.HasPrefix(src, "data:")) {
+			src = dataSrc
+		}
+		if src == "" || strings.HasPrefix(src, "data:") {
+			continue
+		}
+		if htmlconv.Attr(token, "width") == "1" && htmlconv.Attr(token, "height") == "1" {
+			continue
+		}
+		return src
+	}
+}
+
+// resolveImageURL は、画像URLを記事のURLを基準に絶対URLに解決する
+// http(s) 以外のURLは空文字列を返す
+func resolveImageURL(imageURL, articleURL string) string {
+	imageURL = strings.TrimSpace(imageURL)
+	if imageURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return ""
+	}
+
+	if !u.IsAbs() {
+		base, err := url.Parse(articleURL)
+		if err != nil || !base.IsAbs() {
+			return ""
+		}
+		u = base.ResolveReference(u)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// EnrichImages は、画像が見つからなかった記事について記事ページの og:image / twitter:image を取得する
+// fetch_og_image が有効なフィードの記事のみが対象。通知対象の記事に絞ってから呼び出すこと
+// フィードの取得と同じく、同時取得数は全体とホストごとの上限で制限する
+func (f *Fetcher) EnrichImages(ctx context.Context, articles []*models.Article, feedConfigs []*models.FeedConfig) {
+	feedsByURL := make(map[string]*models.FeedConfig, len(feedConfigs))
+	for _, fc := range feedConfigs {
+		feedsByURL[fc.URL] = fc
+	}
+
+	// 記事ページごとの取得設定（記事ページはフィードと別ホストの場合があるため、認証情報は付与しない）
+	pages := make([]*models.FeedConfig, 0)
+	articlesByPage := make(map[*models.FeedConfig]*models.Article)
+	for _, article := range articles {
+		fc, ok := feedsByURL[article.FeedURL]
+		if !ok || !fc.FetchOGImage || article.ImageURL != "" {
+			continue
+		}
+
+		page := &models.FeedConfig{
+			Name:               article.Title,
+			URL:                article.URL,
+			UserAgent:          fc.UserAgent,
+			InsecureSkipVerify: fc.InsecureSkipVerify,
+		}
+		pages = append(pages, page)
+		articlesByPage[page] = article
+	}
+	if len(pages) == 0 {
+		return
+	}
+
+	// ワーカー数は全体の上限とページ数の小さい方
+	workers := f.maxConcurrency
+	if workers > len(pages) {
+		workers = len(pages)
+	}
+
+	// 各記事は1つのワーカーのみが更新するため、記事への書き込みは競合しない
+	scheduler := newHostScheduler(pages, f.maxConcurrencyPerHost)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				page, host := scheduler.acquire()
+				if page == nil {
+					return
+				}
+
+				f.enrichImage(ctx, articlesByPage[page], page)
+				scheduler.release(host)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// enrichImage は、記事ページから取得した画像URLを記事に設定する
+func (f *Fetcher) enrichImage(ctx context.Context, article *models.Article, page *models.FeedConfig) {
+	imageURL, err := f.fetchPageImage(ctx, page)
+	if err != nil {
+		logger.Debug("記事ページから画像を取得できませんでした",
+			"title", article.Title,
+			"url", article.URL,
+			"error", err)
+		return
+	}
+
+	if imageURL != "" {
+		article.ImageURL = imageURL
+		logger.Debug("記事ページから画像を取得しました",
+			"title", article.Title,
+			"image_url", imageURL)
+	}
+}
+
+// fetchPageImage は、記事ページを取得して og:image / twitter:image の画像URLを返す
+func (f *Fetcher) fetchPageImage(ctx context.Context, page *models.FeedConfig) (string, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	resp, err := f.get(fetchCtx, page.URL, page, models.CacheValidators{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	for _, selector := range pageImageSelectors {
+		content, ok := doc.Find(selector).First().Attr("content")
+		if !ok {
+			continue
+		}
+		if imageURL := resolveImageURL(content, resp.Request.URL.String()); imageURL != "" {
+			return imageURL, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,218 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+)
+
+// TestExtractImageURL は、各種ソースからの画像URL抽出をテストする
+func TestExtractImageURL(t *testing.T) {
+	fetcher := NewFetcher(30 * time.Second)
+	articleURL := "https://example.com/posts/1"
+
+	tests := []struct {
+		name string
+		item *gofeed.Item
+		want string
+	}{
+		{
+			name: "Item.Image",
+			item: &gofeed.Item{Image: &gofeed.Image{URL: "https://example.com/image.png"}},
+			want: "https://example.com/image.png",
+		},
+		{
+			name: "media:thumbnail",
+			item: &gofeed.Item{Extensions: ext.Extensions{"media": {
+				"thumbnail": {{Name: "thumbnail", Attrs: map[string]string{"url": "https://i.ytimg.com/vi/abc/hqdefault.jpg"}}},
+			}}},
+			want: "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+		},
+		{
+			name: "media:group内のmedia:thumbnail",
+			item: &gofeed.Item{Extensions: ext.Extensions{"media": {
+				"group": {{Name: "group", Children: map[string][]ext.Extension{
+					"thumbnail": {{Name: "thumbnail", Attrs: map[string]string{"url": "https://example.com/group.jpg"}}},
+				}}},
+			}}},
+			want: "https://example.com/group.jpg",
+		},
+		{
+			name: "画像以外のmedia:contentは無視",
+			item: &gofeed.Item{Extensions: ext.Extensions{"media": {
+				"content": {
+					{Name: "content", Attrs: map[string]string{"url": "https://example.com/video.mp4", "medium": "video"}},
+					{Name: "content", Attrs: map[string]string{"url": "https://example.com/photo.jpg", "type": "image/jpeg"}},
+				},
+			}}},
+			want: "https://example.com/photo.jpg",
+		},
+		{
+			name: "画像のEnclosure",
+			item: &gofeed.Item{Enclosures: []*gofeed.Enclosure{
+				{URL: "https://example.com/audio.mp3", Type: "audio/mpeg"},
+				{URL: "https://example.com/cover.jpg", Type: "image/jpeg"},
+			}},
+			want: "https://example.com/cover.jpg",
+		},
+		{
+			name: "iTunesの画像",
+			item: &gofeed.Item{ITunesExt: &ext.ITunesItemExtension{Image: "https://example.com/podcast.jpg"}},
+			want: "https://example.com/podcast.jpg",
+		},
+		{
+			name: "本文の最初のimg（相対URLを解決）",
+			item: &gofeed.Item{Content: `<p>text</p><img src="/images/first.png"><img src="/images/second.png">`},
+			want: "https://example.com/images/first.png",
+		},
+		{
+			name: "トラッキング画像とdata URIをスキップ",
+			item: &gofeed.Item{Description: `<img src="https://t.example.com/p.gif" width="1" height="1"><img src="data:image/gif;base64,R0lGOD" data-src="lazy.jpg">`},
+			want: "https://example.com/posts/lazy.jpg",
+		},
+		{
+			name: "画像なし",
+			item: &gofeed.Item{Description: "<p>no image</p>"},
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fetcher.extractImageURL(tt.item, articleURL)
+			if got != tt.want {
+				t.Errorf("extractImageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestResolveImageURL は、画像URLの解決をテストする
+func TestResolveImageURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		imageURL   string
+		articleURL string
+		want       string
+	}{
+		{"絶対URL", "https://cdn.example.com/a.png", "https://example.com/p", "https://cdn.example.com/a.png"},
+		{"ルート相対", "/a.png", "https://example.com/posts/1", "https://example.com/a.png"},
+		{"プロトコル相対", "//cdn.example.com/a.png", "https://example.com/p", "https://cdn.example.com/a.png"},
+		{"基準URLなしの相対URL", "a.png", "", ""},
+		{"http以外のスキーム", "javascript:alert(1)", "https://example.com/p", ""},
+		{"空文字列", "", "https://example.com/p", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveImageURL(tt.imageURL, tt.articleURL)
+			if got != tt.want {
+				t.Errorf("resolveImageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEnrichImages は、記事ページの og:image 取得をテストする
+func TestEnrichImages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "" {
+			t.Error("auth headers should not be sent to article pages")
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head>
+<meta name="twitter:image" content="/twitter.png">
+<meta property="og:image" content="/og.png">
+</head><body></body></html>`))
+	}))
+	defer server.Close()
+
+	feeds := []*models.FeedConfig{
+		{
+			Name:         "Opt-in",
+			URL:          "https://example.com/feed",
+			FetchOGImage: true,
+			Auth:         &models.FeedAuth{BearerToken: "secret"},
+		},
+		{Name: "Default", URL: "https://example.com/other"},
+	}
+	articles := []*models.Article{
+		{Title: "no image", URL: server.URL + "/posts/1", FeedURL: "https://example.com/feed"},
+		{Title: "has image", URL: server.URL + "/posts/2", FeedURL: "https://example.com/feed", ImageURL: "https://example.com/existing.png"},
+		{Title: "not opted in", URL: server.URL + "/posts/3", FeedURL: "https://example.com/other"},
+	}
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.EnrichImages(context.Background(), articles, feeds)
+
+	if articles[0].ImageURL != server.URL+"/og.png" {
+		t.Errorf("ImageURL = %q, want %q", articles[0].ImageURL, server.URL+"/og.png")
+	}
+	if articles[1].ImageURL != "https://example.com/existing.png" {
+		t.Errorf("existing ImageURL should be kept, got %q", articles[1].ImageURL)
+	}
+	if articles[2].ImageURL != "" {
+		t.Errorf("feeds without fetch_og_image should not be enriched, got %q", articles[2].ImageURL)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+// TestEnrichImagesConcurrencyPerHost は、記事ページの取得がホストごとの同時取得数の上限を守ることをテストする
+func TestEnrichImagesConcurrencyPerHost(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := 0
+	maxInFlight := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><meta property="og:image" content="/og.png"></head></html>`))
+	}))
+	defer server.Close()
+
+	feeds := []*models.FeedConfig{{Name: "Opt-in", URL: "https://example.com/feed", FetchOGImage: true}}
+	articles := make([]*models.Article, 0)
+	for i := 0; i < 8; i++ {
+		articles = append(articles, &models.Article{
+			Title:   "no image",
+			URL:     server.URL + "/posts/" + string(rune('a'+i)),
+			FeedURL: "https://example.com/feed",
+		})
+	}
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetConcurrency(10, 2)
+	fetcher.EnrichImages(context.Background(), articles, feeds)
+
+	for i, article := range articles {
+		if article.ImageURL != server.URL+"/og.png" {
+			t.Errorf("articles[%d].ImageURL = %q, want %q", i, article.ImageURL, server.URL+"/og.png")
+		}
+	}
+	if maxInFlight > 2 {
+		t.Errorf("max in-flight requests = %d, want <= 2", maxInFlight)
+	}
+}
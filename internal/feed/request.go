@@ -0,0 +1,89 @@
+package feed
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// DefaultUserAgent は、フィード取得時に使用するデフォルトのUser-Agent
+const DefaultUserAgent = "rss-discord-notifier/1.0 (+https://github.com/ken344/rss-discord-notifier)"
+
+// maxRedirects は、フィード取得時にたどるリダイレクトの上限（net/http の既定と同じ）
+const maxRedirects = 10
+
+// setRequestHeaders は、フィード設定に応じてリクエストヘッダーを設定する
+// 優先順位: headers > auth > user_agent > デフォルト
+// 認証情報と headers は、フィードURLと同じホストへのリクエストのみに付与する
+// （自動検出したフィードURLが別のホストを指す場合に認証情報を漏らさないため）
+func setRequestHeaders(req *http.Request, feedConfig *models.FeedConfig, defaultUserAgent string) {
+	// User-Agent（フィード設定 > デフォルト）
+	req.Header.Set("User-Agent", userAgentFor(feedConfig, defaultUserAgent))
+
+	if !isSameHost(req.URL, feedConfig.URL) {
+		return
+	}
+
+	// 認証情報
+	if auth := feedConfig.Auth; auth != nil {
+		if auth.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+		} else if auth.Username != "" {
+			req.SetBasicAuth(auth.Username, auth.Password)
+		}
+
+		if auth.Cookie != "" {
+			req.Header.Set("Cookie", auth.Cookie)
+		}
+	}
+
+	// 任意のヘッダー（明示的な指定を最優先）
+	for name, value := range feedConfig.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// redirectPolicy は、フィード取得時のリダイレクトの扱いを返す（http.Client.CheckRedirect に設定する）
+// net/http はリダイレクト先にも元のリクエストのヘッダーをコピーするため、
+// 別のホストへのリダイレクトでは認証情報と headers を取り除く
+func redirectPolicy(feedConfig *models.FeedConfig, defaultUserAgent string) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		if isSameHost(req.URL, feedConfig.URL) {
+			return nil
+		}
+
+		req.Header.Del("Authorization")
+		req.Header.Del("Cookie")
+		for name := range feedConfig.Headers {
+			req.Header.Del(name)
+		}
+		// headers で上書きした User-Agent も取り除かれるため設定し直す
+		req.Header.Set("User-Agent", userAgentFor(feedConfig, defaultUserAgent))
+
+		return nil
+	}
+}
+
+// userAgentFor は、フィードの取得に使用するUser-Agentを返す（フィード設定 > デフォルト）
+func userAgentFor(feedConfig *models.FeedConfig, defaultUserAgent string) string {
+	if feedConfig.UserAgent != "" {
+		return feedConfig.UserAgent
+	}
+	return defaultUserAgent
+}
+
+// isSameHost は、リクエストのURLがフィードURLと同じホスト（ポートを含む）かどうかを返す
+func isSameHost(u *url.URL, feedURL string) bool {
+	fu, err := url.Parse(feedURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, fu.Host)
+}
@@ -0,0 +1,157 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestSetRequestHeaders は、フィード設定に応じたリクエストヘッダーの設定をテストする
+func TestSetRequestHeaders(t *testing.T) {
+	tests := []struct {
+		name       string
+		feedConfig *models.FeedConfig
+		requestURL string
+		want       map[string]string
+	}{
+		{
+			name:       "デフォルトのUser-Agent",
+			feedConfig: &models.FeedConfig{},
+			want: map[string]string{
+				"User-Agent":    DefaultUserAgent,
+				"Authorization": "",
+			},
+		},
+		{
+			name: "Bearerトークンとカスタム User-Agent",
+			feedConfig: &models.FeedConfig{
+				UserAgent: "custom-agent/1.0",
+				Auth:      &models.FeedAuth{BearerToken: "secret"},
+			},
+			want: map[string]string{
+				"User-Agent":    "custom-agent/1.0",
+				"Authorization": "Bearer secret",
+			},
+		},
+		{
+			name: "Basic認証とCookie",
+			feedConfig: &models.FeedConfig{
+				Auth: &models.FeedAuth{Username: "user", Password: "pass", Cookie: "session=abc"},
+			},
+			want: map[string]string{
+				"Authorization": "Basic dXNlcjpwYXNz",
+				"Cookie":        "session=abc",
+			},
+		},
+		{
+			name: "headersが最優先",
+			feedConfig: &models.FeedConfig{
+				UserAgent: "custom-agent/1.0",
+				Headers: map[string]string{
+					"User-Agent":      "header-agent/1.0",
+					"PRIVATE-TOKEN":   "gitlab-token",
+					"Accept-Language": "ja",
+				},
+			},
+			want: map[string]string{
+				"User-Agent":      "header-agent/1.0",
+				"Private-Token":   "gitlab-token",
+				"Accept-Language": "ja",
+			},
+		},
+		{
+			name: "別のホストには認証情報とheadersを付与しない",
+			feedConfig: &models.FeedConfig{
+				URL:       "https://example.com/",
+				UserAgent: "custom-agent/1.0",
+				Auth:      &models.FeedAuth{BearerToken: "secret", Cookie: "session=abc"},
+				Headers:   map[string]string{"PRIVATE-TOKEN": "gitlab-token"},
+			},
+			requestURL: "https://feeds.other.example/feed.xml",
+			want: map[string]string{
+				"User-Agent":    "custom-agent/1.0",
+				"Authorization": "",
+				"Cookie":        "",
+				"Private-Token": "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.feedConfig.URL == "" {
+				tt.feedConfig.URL = "https://example.com/"
+			}
+			requestURL := tt.requestURL
+			if requestURL == "" {
+				requestURL = "https://example.com/feed"
+			}
+
+			req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+
+			setRequestHeaders(req, tt.feedConfig, DefaultUserAgent)
+
+			for name, want := range tt.want {
+				if got := req.Header.Get(name); got != want {
+					t.Errorf("header %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestRedirectPolicy は、別のホストへのリダイレクトで認証情報とheadersが取り除かれることをテストする
+func TestRedirectPolicy(t *testing.T) {
+	var redirectedHeader http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirectedHeader = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(testRSS))
+	}))
+	defer other.Close()
+
+	var originHeader http.Header
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		originHeader = r.Header.Clone()
+		http.Redirect(w, r, other.URL+"/feed.xml", http.StatusFound)
+	}))
+	defer origin.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetRetryPolicy(0, 0)
+
+	articles, err := fetcher.Fetch(context.Background(), &models.FeedConfig{
+		Name:      "Private Feed",
+		URL:       origin.URL + "/feed.xml",
+		UserAgent: "custom-agent/1.0",
+		Auth:      &models.FeedAuth{BearerToken: "secret"},
+		Headers:   map[string]string{"PRIVATE-TOKEN": "gitlab-token", "User-Agent": "header-agent/1.0"},
+		Enabled:   true,
+	})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("articles length = %d, want 1", len(articles))
+	}
+
+	if got := originHeader.Get("Private-Token"); got != "gitlab-token" {
+		t.Errorf("origin Private-Token = %q, want gitlab-token", got)
+	}
+
+	for _, name := range []string{"Authorization", "Private-Token"} {
+		if got := redirectedHeader.Get(name); got != "" {
+			t.Errorf("redirected header %s = %q, want empty", name, got)
+		}
+	}
+	if got := redirectedHeader.Get("User-Agent"); got != "custom-agent/1.0" {
+		t.Errorf("redirected User-Agent = %q, want custom-agent/1.0", got)
+	}
+}
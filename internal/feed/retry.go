@@ -0,0 +1,135 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// HTTPError は、フィード取得時にサーバーから返されたHTTPエラー
+type HTTPError struct {
+	// StatusCode はHTTPステータスコード
+	StatusCode int
+
+	// Status はHTTPステータス文字列
+	Status string
+
+	// RetryAfter は Retry-After ヘッダーで指定された待機時間（指定がない場合は0）
+	RetryAfter time.Duration
+}
+
+// Error は、エラーメッセージを返す
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http error: %s", e.Status)
+}
+
+// Temporary は、一時的なエラー（リトライで回復する可能性があるもの）かどうかを返す
+func (e *HTTPError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryable は、フィード取得のエラーがリトライ対象かどうかを判定する
+// タイムアウト・接続の拒否や切断・一時的なHTTPエラーのみをリトライし、
+// 404などの恒久的なエラー、証明書のエラー、未対応のスキーム、リダイレクトのループやパースエラーはリトライしない
+func isRetryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Temporary()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfterOf は、エラーに含まれる Retry-After の待機時間を返す
+func retryAfterOf(err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter は、Retry-After ヘッダーの値を待機時間に変換する
+// 秒数とHTTP日付の両方の形式に対応する
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// backoffDelay は、試行回数に応じた指数バックオフの待機時間を返す
+// 待機時間の半分をランダムにずらして（ジッター）、同時リトライの集中を避ける
+func backoffDelay(attempt int, baseDelay, maxDelay time.Duration) time.Duration {
+	delay := baseDelay
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryPolicy は、フィードに適用するリトライ回数と最大待機時間を返す
+// フィード個別の設定がある場合はそちらを優先する
+func (f *Fetcher) retryPolicy(feedConfig *models.FeedConfig) (int, time.Duration) {
+	maxRetries := f.maxRetries
+	if feedConfig.MaxRetries != nil && *feedConfig.MaxRetries >= 0 {
+		maxRetries = *feedConfig.MaxRetries
+	}
+
+	maxDelay := f.retryMaxDelay
+	if feedConfig.RetryMaxDelaySeconds > 0 {
+		maxDelay = time.Duration(feedConfig.RetryMaxDelaySeconds) * time.Second
+	}
+
+	return maxRetries, maxDelay
+}
@@ -0,0 +1,203 @@
+package feed
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestFetchRetry は、一時的なエラー後のリトライをテストする
+func TestFetchRetry(t *testing.T) {
+	attemptCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		if attemptCount == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if attemptCount == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(testRSS))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetRetryPolicy(3, time.Second)
+	fetcher.SetRetryBaseDelay(10 * time.Millisecond) // テストを高速化
+
+	feedConfig := &models.FeedConfig{Name: "Test Feed", URL: server.URL, Enabled: true}
+
+	articles, err := fetcher.Fetch(context.Background(), feedConfig)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v, want nil (should succeed after retry)", err)
+	}
+	if len(articles) != 1 {
+		t.Errorf("articles length = %d, want 1", len(articles))
+	}
+	if attemptCount != 3 {
+		t.Errorf("attempt count = %d, want 3", attemptCount)
+	}
+}
+
+// TestFetchNoRetryOnPermanentError は、恒久的なエラーをリトライしないことをテストする
+func TestFetchNoRetryOnPermanentError(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "404 Not Found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+		},
+		{
+			name: "パースエラー",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("not a feed"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attemptCount := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attemptCount++
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			fetcher := NewFetcher(5 * time.Second)
+			fetcher.SetRetryPolicy(3, time.Second)
+			fetcher.SetRetryBaseDelay(10 * time.Millisecond)
+
+			feedConfig := &models.FeedConfig{Name: "Test Feed", URL: server.URL, Enabled: true}
+			if _, err := fetcher.Fetch(context.Background(), feedConfig); err == nil {
+				t.Fatal("Fetch() should return error")
+			}
+			if attemptCount != 1 {
+				t.Errorf("attempt count = %d, want 1", attemptCount)
+			}
+		})
+	}
+}
+
+// TestFetchRetryPerFeed は、フィード個別のリトライ回数をテストする
+func TestFetchRetryPerFeed(t *testing.T) {
+	attemptCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	fetcher.SetRetryPolicy(5, time.Second)
+	fetcher.SetRetryBaseDelay(time.Millisecond)
+
+	maxRetries := 1
+	feedConfig := &models.FeedConfig{Name: "Test Feed", URL: server.URL, Enabled: true, MaxRetries: &maxRetries}
+
+	_, err := fetcher.Fetch(context.Background(), feedConfig)
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Fetch() error = %v, want HTTPError with status 502", err)
+	}
+	if attemptCount != 2 {
+		t.Errorf("attempt count = %d, want 2", attemptCount)
+	}
+}
+
+// timeoutError は、タイムアウトを表す net.Error
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// TestIsRetryable は、リトライ対象のエラーの判定をテストする
+func TestIsRetryable(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Get", URL: "https://example.com/feed", Err: err}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"一時的なHTTPエラー", &HTTPError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"恒久的なHTTPエラー", &HTTPError{StatusCode: http.StatusNotFound}, false},
+		{"タイムアウト", urlError(timeoutError{}), true},
+		{"コンテキストのタイムアウト", fmt.Errorf("fetch: %w", context.DeadlineExceeded), true},
+		{"接続の拒否", urlError(&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}), true},
+		{"接続のリセット", urlError(&net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}), true},
+		{"レスポンスの途中での切断", urlError(io.ErrUnexpectedEOF), true},
+		{"証明書のエラー", urlError(x509.UnknownAuthorityError{}), false},
+		{"未対応のスキーム", urlError(errors.New(`unsupported protocol scheme "ftp"`)), false},
+		{"リダイレクトのループ", urlError(errors.New("stopped after 10 redirects")), false},
+		{"キャンセル", context.Canceled, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseRetryAfter は、Retry-After ヘッダーのパースをテストする
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 11, 13, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"秒数", "120", 120 * time.Second},
+		{"HTTP日付", "Thu, 13 Nov 2025 10:00:30 GMT", 30 * time.Second},
+		{"過去の日付", "Thu, 13 Nov 2025 09:00:00 GMT", 0},
+		{"空文字列", "", 0},
+		{"不正な値", "soon", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBackoffDelay は、指数バックオフの待機時間が上限を超えないことをテストする
+func TestBackoffDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	maxDelay := 1 * time.Second
+
+	for attempt := 0; attempt < 10; attempt++ {
+		got := backoffDelay(attempt, base, maxDelay)
+		if got <= 0 || got > maxDelay {
+			t.Errorf("backoffDelay(%d) = %v, want 0 < delay <= %v", attempt, got, maxDelay)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package feed
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// hostScheduler は、フィードをホストごとのキューに分けて公平に払い出すスケジューラー
+// ホストをラウンドロビンで巡回し、同一ホストへの同時リクエスト数を perHost 以下に抑える
+type hostScheduler struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// queues はホストごとの待ちフィード
+	queues map[string][]*models.FeedConfig
+
+	// hosts はラウンドロビンで巡回するホストの順序
+	hosts []string
+
+	// next は次に巡回を開始するホストのインデックス
+	next int
+
+	// active はホストごとの実行中リクエスト数
+	active map[string]int
+
+	// perHost はホストごとの同時リクエスト数の上限
+	perHost int
+
+	// remaining はまだ払い出していないフィード数
+	remaining int
+}
+
+// newHostScheduler は、新しいスケジューラーを作成する
+func newHostScheduler(feedConfigs []*models.FeedConfig, perHost int) *hostScheduler {
+	s := &hostScheduler{
+		queues:    make(map[string][]*models.FeedConfig),
+		active:    make(map[string]int),
+		perHost:   perHost,
+		remaining: len(feedConfigs),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	for _, fc := range feedConfigs {
+		host := hostOf(fc.URL)
+		if _, exists := s.queues[host]; !exists {
+			s.hosts = append(s.hosts, host)
+		}
+		s.queues[host] = append(s.queues[host], fc)
+	}
+
+	return s
+}
+
+// acquire は、次に取得するフィードを返す
+// 取得可能なフィードがない場合は、他のリクエストが終わるまで待機する
+// すべてのフィードを払い出し済みの場合は nil を返す
+func (s *hostScheduler) acquire() (*models.FeedConfig, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.remaining == 0 {
+			return nil, ""
+		}
+
+		for i := 0; i < len(s.hosts); i++ {
+			idx := (s.next + i) % len(s.hosts)
+			host := s.hosts[idx]
+			queue := s.queues[host]
+			if len(queue) == 0 || (s.perHost > 0 && s.active[host] >= s.perHost) {
+				continue
+			}
+
+			s.queues[host] = queue[1:]
+			s.active[host]++
+			s.remaining--
+			s.next = idx + 1
+			return queue[0], host
+		}
+
+		s.cond.Wait()
+	}
+}
+
+// release は、ホストへのリクエストが完了したことを通知する
+func (s *hostScheduler) release(host string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active[host]--
+	s.cond.Broadcast()
+}
+
+// hostOf は、URLからホスト名を取り出す
+// パースできない場合はURL全体をホストとして扱う
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Hostname())
+}
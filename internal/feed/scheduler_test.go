@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestHostSchedulerRoundRobin は、ホスト間でフィードが交互に払い出されることをテストする
+func TestHostSchedulerRoundRobin(t *testing.T) {
+	feedConfigs := []*models.FeedConfig{
+		{Name: "a1", URL: "https://a.example.com/1"},
+		{Name: "a2", URL: "https://a.example.com/2"},
+		{Name: "a3", URL: "https://a.example.com/3"},
+		{Name: "b1", URL: "https://b.example.com/1"},
+	}
+
+	scheduler := newHostScheduler(feedConfigs, 0)
+
+	want := []string{"a1", "b1", "a2", "a3"}
+	for _, name := range want {
+		fc, host := scheduler.acquire()
+		if fc == nil {
+			t.Fatalf("acquire() returned nil, want %s", name)
+		}
+		if fc.Name != name {
+			t.Errorf("acquire() = %s, want %s", fc.Name, name)
+		}
+		scheduler.release(host)
+	}
+
+	if fc, _ := scheduler.acquire(); fc != nil {
+		t.Errorf("acquire() = %s, want nil after all feeds", fc.Name)
+	}
+}
+
+// TestHostOf は、URLからのホスト名抽出をテストする
+func TestHostOf(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://Qiita.com/tags/go/feed", "qiita.com"},
+		{"https://zenn.dev:443/topics/go/feed", "zenn.dev"},
+		{"invalid-url", "invalid-url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := hostOf(tt.input); got != tt.want {
+				t.Errorf("hostOf(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
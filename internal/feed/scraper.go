@@ -0,0 +1,158 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+	"github.com/mmcdole/gofeed"
+)
+
+// scrapeDateLayouts は、公開日時のフォーマットが指定されていない場合に試す形式
+var scrapeDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006.01.02",
+	"2006年1月2日",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// scrapeResponse は、レスポンスのHTMLからCSSセレクターで記事を抽出する
+func (f *Fetcher) scrapeResponse(resp *http.Response, feedConfig *models.FeedConfig) (*gofeed.Feed, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return scrapeHTML(body, resp.Request.URL, feedConfig.Selectors)
+}
+
+// scrapeHTML は、HTMLページから記事を抽出し、RSSフィードと同じ形式（gofeed.Feed）に変換する
+// 変換後はRSSフィードと同じ処理（記事への変換・既読チェック・通知）を通る
+func scrapeHTML(body []byte, pageURL *url.URL, selectors *models.HTMLSelectors) (*gofeed.Feed, error) {
+	if selectors == nil || selectors.Item == "" {
+		return nil, fmt.Errorf("selectors.item is required for html source")
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	feed := &gofeed.Feed{
+		Title: strings.TrimSpace(doc.Find("title").First().Text()),
+		Link:  pageURL.String(),
+		Items: make([]*gofeed.Item, 0),
+	}
+
+	doc.Find(selectors.Item).Each(func(_ int, s *goquery.Selection) {
+		if item := scrapeItem(s, pageURL, selectors); item != nil {
+			feed.Items = append(feed.Items, item)
+		}
+	})
+
+	return feed, nil
+}
+
+// scrapeItem は、記事要素から1件分の記事を抽出する
+// タイトルを取得できない場合は nil を返す
+func scrapeItem(s *goquery.Selection, pageURL *url.URL, selectors *models.HTMLSelectors) *gofeed.Item {
+	// タイトル
+	titleSel := s
+	if selectors.Title != "" {
+		titleSel = s.Find(selectors.Title).First()
+	}
+	title := strings.Join(strings.Fields(titleSel.Text()), " ")
+	if title == "" {
+		return nil
+	}
+
+	// リンク（記事要素自体が a の場合はその href）
+	var linkSel *goquery.Selection
+	switch {
+	case selectors.Link != "":
+		linkSel = s.Find(selectors.Link).First()
+	case goquery.NodeName(s) == "a":
+		linkSel = s
+	default:
+		linkSel = s.Find("a[href]").First()
+	}
+	link := ""
+	if href, ok := linkSel.Attr("href"); ok {
+		if u, err := pageURL.Parse(strings.TrimSpace(href)); err == nil {
+			link = u.String()
+		}
+	}
+
+	item := &gofeed.Item{
+		Title: title,
+		Link:  link,
+	}
+
+	// リンクがない記事（同一ページ内の変更履歴など）は、ページURLとタイトルから識別子を作る
+	if link == "" {
+		sum := sha1.Sum([]byte(title))
+		item.GUID = pageURL.String() + "#" + hex.EncodeToString(sum[:8])
+		item.Link = pageURL.String()
+	}
+
+	// 公開日時
+	if selectors.Date != "" {
+		dateSel := s.Find(selectors.Date).First()
+		value, ok := dateSel.Attr("datetime")
+		if !ok {
+			value = dateSel.Text()
+		}
+		if t, ok := parseScrapedDate(value, selectors.DateFormat); ok {
+			item.PublishedParsed = &t
+		}
+	}
+
+	// 要約（HTMLのまま渡し、記事への変換時にテキスト化する）
+	if selectors.Summary != "" {
+		if html, err := s.Find(selectors.Summary).First().Html(); err == nil {
+			item.Description = strings.TrimSpace(html)
+		}
+	}
+
+	return item
+}
+
+// parseScrapedDate は、Webページから抽出した日時文字列をパースする
+func parseScrapedDate(value, layout string) (time.Time, bool) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	layouts := scrapeDateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+
+	for _, l := range layouts {
+		if t, err := time.Parse(l, value); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
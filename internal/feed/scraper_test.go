@@ -0,0 +1,124 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// testChangelogHTML は、フィードのない変更履歴ページのサンプル
+const testChangelogHTML = `<!DOCTYPE html>
+<html>
+<head><title>Vendor Changelog</title></head>
+<body>
+  <article class="entry">
+    <h2><a href="/changelog/v2">Version 2.0</a></h2>
+    <time datetime="2025-11-12T09:00:00Z">Nov 12, 2025</time>
+    <div class="body"><p>New <strong>features</strong></p></div>
+  </article>
+  <article class="entry">
+    <h2>Version 1.9</h2>
+    <span class="date">2025-10-01</span>
+    <div class="body"><p>Bug fixes</p></div>
+  </article>
+  <article class="entry">
+    <div class="body">No title</div>
+  </article>
+</body>
+</html>`
+
+// TestScrapeHTML は、CSSセレクターによる記事の抽出をテストする
+func TestScrapeHTML(t *testing.T) {
+	pageURL, _ := url.Parse("https://vendor.example.com/changelog")
+	selectors := &models.HTMLSelectors{
+		Item:    "article.entry",
+		Title:   "h2",
+		Link:    "h2 a",
+		Date:    "time, .date",
+		Summary: ".body",
+	}
+
+	feed, err := scrapeHTML([]byte(testChangelogHTML), pageURL, selectors)
+	if err != nil {
+		t.Fatalf("scrapeHTML() error = %v", err)
+	}
+
+	if feed.Title != "Vendor Changelog" {
+		t.Errorf("Title = %v, want Vendor Changelog", feed.Title)
+	}
+
+	// タイトルのない記事は除外される
+	if len(feed.Items) != 2 {
+		t.Fatalf("Items length = %d, want 2", len(feed.Items))
+	}
+
+	first := feed.Items[0]
+	if first.Title != "Version 2.0" {
+		t.Errorf("Items[0].Title = %v, want Version 2.0", first.Title)
+	}
+	if first.Link != "https://vendor.example.com/changelog/v2" {
+		t.Errorf("Items[0].Link = %v, want resolved absolute URL", first.Link)
+	}
+	if first.PublishedParsed == nil || !first.PublishedParsed.Equal(time.Date(2025, 11, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Items[0].PublishedParsed = %v, want 2025-11-12T09:00:00Z", first.PublishedParsed)
+	}
+
+	// リンクがない記事はページURLとタイトルから識別子を作る
+	second := feed.Items[1]
+	if second.Link != pageURL.String() {
+		t.Errorf("Items[1].Link = %v, want %v", second.Link, pageURL.String())
+	}
+	if second.GUID == "" || second.GUID == second.Link {
+		t.Errorf("Items[1].GUID = %v, want unique ID derived from title", second.GUID)
+	}
+	if second.PublishedParsed == nil || second.PublishedParsed.Format("2006-01-02") != "2025-10-01" {
+		t.Errorf("Items[1].PublishedParsed = %v, want 2025-10-01", second.PublishedParsed)
+	}
+}
+
+// TestFetchHTMLSource は、type: html のフィードが記事に変換されることをテストする
+func TestFetchHTMLSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testChangelogHTML))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(5 * time.Second)
+	feedConfig := &models.FeedConfig{
+		Name:     "Vendor Changelog",
+		URL:      server.URL + "/changelog",
+		Type:     models.FeedTypeHTML,
+		Category: "Tech",
+		Enabled:  true,
+		Selectors: &models.HTMLSelectors{
+			Item:    "article.entry",
+			Title:   "h2",
+			Summary: ".body",
+		},
+	}
+
+	articles, err := fetcher.Fetch(context.Background(), feedConfig)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("articles length = %d, want 2", len(articles))
+	}
+
+	article := articles[0]
+	if article.URL != server.URL+"/changelog/v2" {
+		t.Errorf("URL = %v, want %v", article.URL, server.URL+"/changelog/v2")
+	}
+	if article.Description != "New features" {
+		t.Errorf("Description = %q, want %q", article.Description, "New features")
+	}
+	if article.FeedURL != feedConfig.URL || article.Category != "Tech" {
+		t.Errorf("FeedURL / Category = %v / %v, want values from feed config", article.FeedURL, article.Category)
+	}
+}
@@ -0,0 +1,161 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// futureTolerance は、公開日時が未来の記事を保留せずに通知する許容範囲
+// フィード側のサーバーの時計のずれを吸収するため
+const futureTolerance = 5 * time.Minute
+
+// sinceLayouts は、since に指定できる日時の形式
+var sinceLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// AgeResult は、公開日時による判定結果
+type AgeResult int
+
+const (
+	// AgeOK は通知対象の期間内
+	AgeOK AgeResult = iota
+
+	// AgeTooOld は max_age または since より古い
+	AgeTooOld
+
+	// AgeFuture は公開日時が未来（公開日時まで保留する）
+	AgeFuture
+)
+
+// AgePolicy は、フィードごとの公開日時の範囲（max_age / since）で記事を判定する構造体
+type AgePolicy struct {
+	// global はフィードに指定がない場合に使用する範囲
+	global ageWindow
+
+	// feeds はフィードURLごとの範囲（グローバル設定を反映済み）
+	feeds map[string]ageWindow
+}
+
+// ageWindow は、公開日時の範囲
+type ageWindow struct {
+	// maxAge は現在時刻からの最大経過時間（0は制限なし）
+	maxAge time.Duration
+
+	// since はこれより前に公開された記事を除外する日時（ゼロ値は制限なし）
+	since time.Time
+}
+
+// NewAgePolicy は、通知設定とフィード設定から公開日時の判定ポリシーを作成する
+// フィードの max_age / since はグローバル設定より優先され、"0" を指定するとグローバル設定を無効化できる
+func NewAgePolicy(notification *models.NotificationConfig, feedConfigs []*models.FeedConfig) (*AgePolicy, error) {
+	p := &AgePolicy{feeds: make(map[string]ageWindow)}
+
+	if notification != nil {
+		window, err := parseWindow(ageWindow{}, notification.MaxAge, notification.Since)
+		if err != nil {
+			return nil, fmt.Errorf("notification: %w", err)
+		}
+		p.global = window
+	}
+
+	for _, fc := range feedConfigs {
+		window, err := parseWindow(p.global, fc.MaxAge, fc.Since)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", fc.Name, err)
+		}
+		p.feeds[fc.URL] = window
+	}
+
+	return p, nil
+}
+
+// parseWindow は、max_age / since を解析して base を上書きした範囲を返す
+func parseWindow(base ageWindow, maxAge, since string) (ageWindow, error) {
+	window := base
+
+	if maxAge != "" {
+		d, err := ParseMaxAge(maxAge)
+		if err != nil {
+			return ageWindow{}, err
+		}
+		window.maxAge = d
+	}
+
+	if since != "" {
+		t, err := ParseSince(since)
+		if err != nil {
+			return ageWindow{}, err
+		}
+		window.since = t
+	}
+
+	return window, nil
+}
+
+// Check は、記事の公開日時が通知対象の範囲内かを判定する
+// 公開日時が未来の記事は、範囲の指定の有無にかかわらず AgeFuture
+func (p *AgePolicy) Check(article *models.Article, now time.Time) AgeResult {
+	if article.PublishedAt.After(now.Add(futureTolerance)) {
+		return AgeFuture
+	}
+
+	window, ok := p.feeds[article.FeedURL]
+	if !ok {
+		window = p.global
+	}
+
+	if window.maxAge > 0 && article.PublishedAt.Before(now.Add(-window.maxAge)) {
+		return AgeTooOld
+	}
+
+	if !window.since.IsZero() && article.PublishedAt.Before(window.since) {
+		return AgeTooOld
+	}
+
+	return AgeOK
+}
+
+// ParseMaxAge は、max_age の値を解析する
+// Goの time.ParseDuration の形式（例: 72h, 90m）に加えて日数（例: 7d）を指定できる。"0" は制限なし
+func ParseMaxAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid max_age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid max_age %q", s)
+	}
+	return d, nil
+}
+
+// ParseSince は、since の値を解析する
+// RFC3339 または日付（例: 2025-01-01、UTCとして扱う）を指定できる。"0" は制限なし
+func ParseSince(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range sinceLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid since %q", s)
+}
@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestAgePolicyCheck は、公開日時による判定をテストする
+func TestAgePolicyCheck(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+
+	notification := &models.NotificationConfig{MaxAge: "72h"}
+	feeds := []*models.FeedConfig{
+		{Name: "Global", URL: "https://example.com/global"},
+		{Name: "Week", URL: "https://example.com/week", MaxAge: "7d"},
+		{Name: "Unlimited", URL: "https://example.com/unlimited", MaxAge: "0"},
+		{Name: "Since", URL: "https://example.com/since", MaxAge: "0", Since: "2025-11-01"},
+	}
+
+	policy, err := NewAgePolicy(notification, feeds)
+	if err != nil {
+		t.Fatalf("NewAgePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		feedURL     string
+		publishedAt time.Time
+		want        AgeResult
+	}{
+		{"グローバルの範囲内", "https://example.com/global", now.Add(-48 * time.Hour), AgeOK},
+		{"グローバルの範囲外", "https://example.com/global", now.Add(-96 * time.Hour), AgeTooOld},
+		{"フィードの設定が優先", "https://example.com/week", now.Add(-96 * time.Hour), AgeOK},
+		{"フィードの設定の範囲外", "https://example.com/week", now.AddDate(0, 0, -8), AgeTooOld},
+		{"0で制限なし", "https://example.com/unlimited", now.AddDate(-5, 0, 0), AgeOK},
+		{"制限なしでも未来の記事は保留", "https://example.com/unlimited", now.Add(24 * time.Hour), AgeFuture},
+		{"since以降", "https://example.com/since", time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC), AgeOK},
+		{"sinceより前", "https://example.com/since", time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC), AgeTooOld},
+		{"未来の記事は保留", "https://example.com/global", now.Add(2 * time.Hour), AgeFuture},
+		{"時計のずれは許容", "https://example.com/global", now.Add(time.Minute), AgeOK},
+		{"設定にないフィードはグローバル", "https://example.com/other", now.Add(-96 * time.Hour), AgeTooOld},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := &models.Article{FeedURL: tt.feedURL, PublishedAt: tt.publishedAt}
+			if got := policy.Check(article, now); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAgePolicyCheckWithoutWindow は、範囲の指定がなくても未来の記事を保留することをテストする
+func TestAgePolicyCheckWithoutWindow(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+
+	policy, err := NewAgePolicy(nil, []*models.FeedConfig{{Name: "Feed", URL: "https://example.com/feed"}})
+	if err != nil {
+		t.Fatalf("NewAgePolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		publishedAt time.Time
+		want        AgeResult
+	}{
+		{"古い記事も通知", now.AddDate(-5, 0, 0), AgeOK},
+		{"時計のずれは許容", now.Add(time.Minute), AgeOK},
+		{"未来の記事は保留", now.Add(24 * time.Hour), AgeFuture},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := &models.Article{FeedURL: "https://example.com/feed", PublishedAt: tt.publishedAt}
+			if got := policy.Check(article, now); got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewAgePolicyInvalid は、無効な max_age / since でエラーになることをテストする
+func TestNewAgePolicyInvalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		notification *models.NotificationConfig
+		feed         *models.FeedConfig
+	}{
+		{"フィードの無効なmax_age", nil, &models.FeedConfig{Name: "Feed", URL: "https://example.com/feed", MaxAge: "3 days"}},
+		{"フィードの無効なsince", nil, &models.FeedConfig{Name: "Feed", URL: "https://example.com/feed", Since: "yesterday"}},
+		{"全体の無効なmax_age", &models.NotificationConfig{MaxAge: "1w"}, &models.FeedConfig{Name: "Feed", URL: "https://example.com/feed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAgePolicy(tt.notification, []*models.FeedConfig{tt.feed}); err == nil {
+				t.Error("NewAgePolicy() error = nil, want error")
+			}
+		})
+	}
+}
+
+// TestParseMaxAge は、max_age の解析をテストする
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"72h", 72 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0", 0, false},
+		{"-1h", 0, true},
+		{"1w", 0, true},
+		{"xd", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseMaxAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMaxAge(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMaxAge(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseSince は、since の解析をテストする
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"2025-01-01T09:00:00+09:00", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"0", time.Time{}, false},
+		{"2025/01/01", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSince(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
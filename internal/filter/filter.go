@@ -0,0 +1,177 @@
+// Package filter は、フィードごとのキーワードフィルターで記事を絞り込む
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// defaultFields はルールに対象フィールドの指定がない場合に使用するフィールド
+var defaultFields = []string{models.FilterFieldTitle, models.FilterFieldDescription}
+
+// Filter は、フィードごとの include / exclude ルールで記事を判定する構造体
+type Filter struct {
+	// feeds はフィードURLごとのコンパイル済みルール
+	feeds map[string]*feedRules
+}
+
+// feedRules は、1つのフィードのコンパイル済みルール
+type feedRules struct {
+	include []*rule
+	exclude []*rule
+}
+
+// rule は、コンパイル済みのフィルタールール
+type rule struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	fields   []string
+}
+
+// New は、フィード設定からフィルターを作成する
+// ルールを持たないフィードの記事はすべて通過する
+func New(feedConfigs []*models.FeedConfig) (*Filter, error) {
+	f := &Filter{feeds: make(map[string]*feedRules)}
+
+	for _, fc := range feedConfigs {
+		if len(fc.Include) == 0 && len(fc.Exclude) == 0 {
+			continue
+		}
+
+		include, err := compileRules(fc.Include)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: invalid include filter: %w", fc.Name, err)
+		}
+		exclude, err := compileRules(fc.Exclude)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: invalid exclude filter: %w", fc.Name, err)
+		}
+
+		f.feeds[fc.URL] = &feedRules{include: include, exclude: exclude}
+	}
+
+	return f, nil
+}
+
+// compileRules は、フィルタールールをコンパイルする
+func compileRules(rules []*models.FilterRule) ([]*rule, error) {
+	compiled := make([]*rule, 0, len(rules))
+
+	for _, r := range rules {
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+
+		c := &rule{fields: r.Fields}
+		if len(c.fields) == 0 {
+			c.fields = defaultFields
+		}
+
+		for _, keyword := range r.Keywords {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				c.keywords = append(c.keywords, strings.ToLower(keyword))
+			}
+		}
+
+		for _, pattern := range r.Patterns {
+			// Validate で検証済みのためエラーにはならない
+			c.patterns = append(c.patterns, regexp.MustCompile(pattern))
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+// Allow は、記事が通知対象かどうかを判定する
+// include が指定されている場合はいずれかに一致する必要があり、exclude のいずれかに一致した場合は除外する
+func (f *Filter) Allow(article *models.Article) bool {
+	rules, ok := f.feeds[article.FeedURL]
+	if !ok {
+		return true
+	}
+
+	if len(rules.include) > 0 && !matchAny(rules.include, article) {
+		return false
+	}
+
+	return !matchAny(rules.exclude, article)
+}
+
+// Matcher は、キーワード・正規表現のルールに記事が一致するかを判定する構造体
+// フィードのフィルター以外（メンションの条件など）でルールを使用する場合に使用する
+type Matcher struct {
+	rules []*rule
+}
+
+// NewMatcher は、ルールから判定器を作成する
+func NewMatcher(rules []*models.FilterRule) (*Matcher, error) {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	return &Matcher{rules: compiled}, nil
+}
+
+// Match は、記事がいずれかのルールに一致するかを判定する（ルールがない場合は常に一致する）
+func (m *Matcher) Match(article *models.Article) bool {
+	if len(m.rules) == 0 {
+		return true
+	}
+	return matchAny(m.rules, article)
+}
+
+// matchAny は、記事がいずれかのルールに一致するかを判定する
+func matchAny(rules []*rule, article *models.Article) bool {
+	for _, r := range rules {
+		if r.match(article) {
+			return true
+		}
+	}
+	return false
+}
+
+// match は、記事がルールに一致するかを判定する
+func (r *rule) match(article *models.Article) bool {
+	for _, field := range r.fields {
+		for _, value := range fieldValues(article, field) {
+			if value == "" {
+				continue
+			}
+
+			lower := strings.ToLower(value)
+			for _, keyword := range r.keywords {
+				if strings.Contains(lower, keyword) {
+					return true
+				}
+			}
+
+			for _, pattern := range r.patterns {
+				if pattern.MatchString(value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// fieldValues は、判定対象フィールドの値を返す
+func fieldValues(article *models.Article, field string) []string {
+	switch field {
+	case models.FilterFieldTitle:
+		return []string{article.Title}
+	case models.FilterFieldDescription:
+		return []string{article.Description}
+	case models.FilterFieldAuthor:
+		return []string{article.Author}
+	case models.FilterFieldCategory:
+		return append([]string{article.Category}, article.Tags...)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,171 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestAllow は、include / exclude ルールによる判定をテストする
+func TestAllow(t *testing.T) {
+	feedURL := "https://example.com/feed"
+
+	tests := []struct {
+		name    string
+		include []*models.FilterRule
+		exclude []*models.FilterRule
+		article *models.Article
+		want    bool
+	}{
+		{
+			name:    "ルールなし",
+			article: &models.Article{Title: "Anything"},
+			want:    true,
+		},
+		{
+			name:    "includeのキーワードに一致（大文字小文字を区別しない）",
+			include: []*models.FilterRule{{Keywords: []string{"kubernetes"}}},
+			article: &models.Article{Title: "Kubernetes 1.30 released"},
+			want:    true,
+		},
+		{
+			name:    "includeに一致しない",
+			include: []*models.FilterRule{{Keywords: []string{"kubernetes"}}},
+			article: &models.Article{Title: "Rust 2.0"},
+			want:    false,
+		},
+		{
+			name:    "includeの正規表現が説明文に一致",
+			include: []*models.FilterRule{{Patterns: []string{`(?i)\bgo\s?1\.\d+`}}},
+			article: &models.Article{Title: "Release notes", Description: "Go 1.22 is out"},
+			want:    true,
+		},
+		{
+			name:    "対象フィールド以外には一致しない",
+			include: []*models.FilterRule{{Keywords: []string{"go"}, Fields: []string{models.FilterFieldAuthor}}},
+			article: &models.Article{Title: "Go news", Author: "Alice"},
+			want:    false,
+		},
+		{
+			name:    "excludeがincludeより優先",
+			include: []*models.FilterRule{{Keywords: []string{"security"}}},
+			exclude: []*models.FilterRule{{Keywords: []string{"sponsored"}}},
+			article: &models.Article{Title: "Sponsored: security scanner"},
+			want:    false,
+		},
+		{
+			name:    "excludeの著者に一致",
+			exclude: []*models.FilterRule{{Keywords: []string{"bot"}, Fields: []string{models.FilterFieldAuthor}}},
+			article: &models.Article{Title: "Weekly update", Author: "release-bot"},
+			want:    false,
+		},
+		{
+			name:    "categoryはフィードのカテゴリと記事のタグの両方が対象",
+			include: []*models.FilterRule{{Keywords: []string{"セキュリティ"}, Fields: []string{models.FilterFieldCategory}}},
+			article: &models.Article{Title: "脆弱性情報", Category: "News", Tags: []string{"クラウド", "セキュリティ"}},
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New([]*models.FeedConfig{
+				{Name: "Test Feed", URL: feedURL, Include: tt.include, Exclude: tt.exclude},
+			})
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			tt.article.FeedURL = feedURL
+			if got := f.Allow(tt.article); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAllowOtherFeed は、ルールが他のフィードの記事に影響しないことをテストする
+func TestAllowOtherFeed(t *testing.T) {
+	f, err := New([]*models.FeedConfig{
+		{
+			Name:    "Filtered",
+			URL:     "https://example.com/filtered",
+			Include: []*models.FilterRule{{Keywords: []string{"go"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	article := &models.Article{Title: "Rust", FeedURL: "https://example.com/other"}
+	if !f.Allow(article) {
+		t.Error("articles from feeds without rules should be allowed")
+	}
+}
+
+// TestNewInvalidRule は、無効なルールでエラーになることをテストする
+func TestNewInvalidRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *models.FilterRule
+	}{
+		{"キーワードも正規表現もない", &models.FilterRule{}},
+		{"無効な正規表現", &models.FilterRule{Patterns: []string{"("}}},
+		{"不明なフィールド", &models.FilterRule{Keywords: []string{"go"}, Fields: []string{"body"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New([]*models.FeedConfig{
+				{Name: "Test Feed", URL: "https://example.com/feed", Exclude: []*models.FilterRule{tt.rule}},
+			})
+			if err == nil {
+				t.Error("New() should return error for invalid rule")
+			}
+		})
+	}
+}
+
+// TestMatcher は、ルールによる記事の判定をテストする
+func TestMatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []*models.FilterRule
+		article *models.Article
+		want    bool
+	}{
+		{
+			name:    "ルールなし",
+			article: &models.Article{Title: "Anything"},
+			want:    true,
+		},
+		{
+			name:    "キーワードに一致",
+			rules:   []*models.FilterRule{{Keywords: []string{"critical"}}},
+			article: &models.Article{Title: "Critical vulnerability in OpenSSL"},
+			want:    true,
+		},
+		{
+			name:    "正規表現に一致しない",
+			rules:   []*models.FilterRule{{Patterns: []string{`CVE-\d+`}}},
+			article: &models.Article{Title: "Weekly newsletter"},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewMatcher(tt.rules)
+			if err != nil {
+				t.Fatalf("NewMatcher() error = %v", err)
+			}
+			if got := m.Match(tt.article); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewMatcher([]*models.FilterRule{{}}); err == nil {
+		t.Error("NewMatcher() should return error for invalid rule")
+	}
+}
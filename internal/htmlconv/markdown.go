@@ -0,0 +1,446 @@
+package htmlconv
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// markdownEscaper は、DiscordのMarkdownとして解釈される記号をエスケープする
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`*`, `\*`,
+	`_`, `\_`,
+	`~`, `\~`,
+	"`", "\\`",
+	`|`, `\|`,
+	`>`, `\>`,
+	`[`, `\[`,
+	`]`, `\]`,
+)
+
+// mentionEscaper は、Discordのメンションとして解釈される記法の間にゼロ幅スペースを挿入する
+var mentionEscaper = strings.NewReplacer(
+	"@everyone", "@\u200beveryone",
+	"@here", "@\u200bhere",
+	"<@", "<\u200b@",
+	"<#", "<\u200b#",
+)
+
+// inlineMarkers は、インライン要素とDiscordのMarkdown記号の対応
+var inlineMarkers = map[string]string{
+	"strong": "**",
+	"b":      "**",
+	"em":     "*",
+	"i":      "*",
+	"u":      "__",
+	"s":      "~~",
+	"del":    "~~",
+	"strike": "~~",
+}
+
+// EscapeMarkdown は、テキスト内のDiscordのMarkdown記号をエスケープする
+// URLはDiscordの自動リンクを壊さないようにエスケープしない
+func EscapeMarkdown(s string) string {
+	words := strings.Split(s, " ")
+	for i, word := range words {
+		words[i] = escapeMarkdownWord(word)
+	}
+	return strings.Join(words, " ")
+}
+
+// EscapeMentions は、テキスト内のDiscordのメンション（@everyone, @here, <@ID>, <@&ID>, <#ID>）を無効化する
+// 表示は変えずにゼロ幅スペースを挿入するため、複数回適用しても結果は変わらない
+func EscapeMentions(s string) string {
+	return mentionEscaper.Replace(s)
+}
+
+// escapeMarkdownWord は、空白を含まない1語をエスケープする
+func escapeMarkdownWord(word string) string {
+	if strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://") {
+		return word
+	}
+	// 見出し記号（# / ## / ###）
+	if word != "" && strings.Trim(word, "#") == "" {
+		return `\` + word
+	}
+	return markdownEscaper.Replace(word)
+}
+
+// markdownFrame は、変換中に内容を一時的に取り込む要素（リンク・コード・引用など）
+type markdownFrame struct {
+	// tag は要素名
+	tag string
+
+	// writer はこの要素の内容の出力先
+	writer *textWriter
+
+	// href はリンク先URL（a要素のみ）
+	href string
+}
+
+// markdownConverter は、HTMLをDiscordのMarkdownに変換する
+type markdownConverter struct {
+	// baseURL は相対URLを解決する基準URL
+	baseURL *url.URL
+
+	// root は最終的な出力先
+	root *textWriter
+
+	// frames は内容を取り込み中の要素のスタック
+	frames []*markdownFrame
+
+	// lists はリストのスタック（順序付きリストの場合は次の番号、それ以外は0）
+	lists []int
+
+	// markers は開いているインライン要素の記号のスタック
+	markers []string
+
+	// skipDepth は除去中の要素の深さ
+	skipDepth int
+}
+
+// ToMarkdown は、HTMLをDiscordのMarkdownに変換する
+// リンクは [text](url)、強調は ** / *、コードは ` / コードブロック、リストは箇条書きに変換し、
+// テキスト内のMarkdown記号はエスケープする。相対URLは baseURL を基準に解決する
+func ToMarkdown(s string, baseURL string) string {
+	c := &markdownConverter{root: newTextWriter()}
+	if u, err := url.Parse(baseURL); err == nil && u.IsAbs() {
+		c.baseURL = u
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// 閉じられていない要素を閉じてから返す
+			for len(c.frames) > 0 {
+				c.closeFrame(c.frames[len(c.frames)-1].tag)
+			}
+			return c.root.String()
+
+		case html.TextToken:
+			if c.skipDepth == 0 {
+				c.text(string(z.Text()))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := z.Token()
+			if skipElements[token.Data] {
+				if tt == html.StartTagToken {
+					c.skipDepth++
+				}
+				continue
+			}
+			if c.skipDepth == 0 {
+				c.startTag(token, tt == html.SelfClosingTagToken)
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skipElements[tag] {
+				if c.skipDepth > 0 {
+					c.skipDepth--
+				}
+				continue
+			}
+			if c.skipDepth == 0 {
+				c.endTag(tag)
+			}
+		}
+	}
+}
+
+// writer は、現在の出力先を返す
+func (c *markdownConverter) writer() *textWriter {
+	if len(c.frames) > 0 {
+		return c.frames[len(c.frames)-1].writer
+	}
+	return c.root
+}
+
+// inFrame は、指定された要素の内容を取り込み中かどうかを返す
+func (c *markdownConverter) inFrame(tag string) bool {
+	for _, f := range c.frames {
+		if f.tag == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// text は、テキストを出力する
+func (c *markdownConverter) text(text string) {
+	w := c.writer()
+	switch {
+	case c.inFrame("pre"):
+		w.writePreformatted(text)
+	case c.inFrame("code"):
+		w.writeText(text)
+	default:
+		for _, word := range splitWords(text) {
+			if word == " " {
+				w.writeText(word)
+				continue
+			}
+			w.writeRaw(escapeMarkdownWord(word))
+		}
+	}
+}
+
+// startTag は、開始タグを処理する
+func (c *markdownConverter) startTag(token html.Token, selfClosing bool) {
+	tag := token.Data
+	w := c.writer()
+
+	// コード内では書式を無視する
+	if c.inFrame("pre") || c.inFrame("code") {
+		if tag == "br" {
+			w.writePreformatted("\n")
+		}
+		return
+	}
+
+	switch {
+	case tag == "br":
+		w.breakLine(1)
+
+	case inlineMarkers[tag] != "":
+		if !selfClosing {
+			c.markers = append(c.markers, inlineMarkers[tag])
+			w.pendingPrefix += inlineMarkers[tag]
+		}
+
+	case tag == "a":
+		if !selfClosing && !c.inFrame("a") {
+			c.frames = append(c.frames, &markdownFrame{tag: "a", writer: newTextWriter(), href: c.resolveURL(Attr(token, "href"))})
+		}
+
+	case tag == "code" || tag == "pre" || tag == "blockquote":
+		if !selfClosing {
+			if tag != "code" {
+				w.breakLine(2)
+			}
+			c.frames = append(c.frames, &markdownFrame{tag: tag, writer: newTextWriter()})
+		}
+
+	case tag == "ul" || tag == "ol":
+		w.breakLine(c.blockBreak())
+		next := 0
+		if tag == "ol" {
+			next = 1
+			if start, err := strconv.Atoi(Attr(token, "start")); err == nil {
+				next = start
+			}
+		}
+		c.lists = append(c.lists, next)
+
+	case tag == "li":
+		w.breakLine(1)
+		w.pendingPrefix = c.listPrefix()
+
+	case isHeading(tag):
+		w.breakLine(c.blockBreak())
+		w.pendingPrefix += "**"
+		c.markers = append(c.markers, "**")
+
+	case paragraphElements[tag]:
+		w.breakLine(c.blockBreak())
+
+	case lineElements[tag]:
+		w.breakLine(1)
+
+	case tag == "td" || tag == "th":
+		w.pendingSpace = true
+	}
+}
+
+// endTag は、終了タグを処理する
+func (c *markdownConverter) endTag(tag string) {
+	w := c.writer()
+
+	if (c.inFrame("pre") && tag != "pre") || (c.inFrame("code") && tag != "code") {
+		return
+	}
+
+	switch {
+	case inlineMarkers[tag] != "":
+		c.closeMarker(w, inlineMarkers[tag])
+
+	case tag == "a" || tag == "code" || tag == "pre" || tag == "blockquote":
+		c.closeFrame(tag)
+
+	case tag == "ul" || tag == "ol":
+		if len(c.lists) > 0 {
+			c.lists = c.lists[:len(c.lists)-1]
+		}
+		w.breakLine(c.blockBreak())
+
+	case isHeading(tag):
+		c.closeMarker(w, "**")
+		w.breakLine(c.blockBreak())
+
+	case paragraphElements[tag]:
+		w.breakLine(c.blockBreak())
+
+	case lineElements[tag]:
+		w.breakLine(1)
+	}
+}
+
+// closeMarker は、インライン要素の終了記号を出力する
+// 中身が空の場合は開始記号ごと取り消す
+func (c *markdownConverter) closeMarker(w *textWriter, marker string) {
+	for i := len(c.markers) - 1; i >= 0; i-- {
+		if c.markers[i] != marker {
+			continue
+		}
+		c.markers = append(c.markers[:i], c.markers[i+1:]...)
+
+		if strings.HasSuffix(w.pendingPrefix, marker) {
+			w.pendingPrefix = strings.TrimSuffix(w.pendingPrefix, marker)
+			return
+		}
+		w.writeSuffix(marker)
+		return
+	}
+}
+
+// closeFrame は、内容を取り込み中の要素を閉じて、親の出力先に書き出す
+func (c *markdownConverter) closeFrame(tag string) {
+	idx := -1
+	for i := len(c.frames) - 1; i >= 0; i-- {
+		if c.frames[i].tag == tag {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return
+	}
+
+	// 内側の閉じられていない要素を先に閉じる
+	for len(c.frames)-1 > idx {
+		c.closeFrame(c.frames[len(c.frames)-1].tag)
+	}
+
+	frame := c.frames[idx]
+	c.frames = c.frames[:idx]
+	parent := c.writer()
+	content := frame.writer.String()
+
+	if frame.writer.leadingSpace {
+		parent.pendingSpace = true
+	}
+
+	switch frame.tag {
+	case "a":
+		switch {
+		case content == "":
+			// 画像のみのリンクなどは出力しない
+		case frame.href == "" || content == frame.href:
+			parent.writeRaw(content)
+		default:
+			parent.writeRaw("[" + content + "](" + EscapeLinkURL(frame.href) + ")")
+		}
+
+	case "code":
+		if content != "" {
+			fence := "`"
+			if strings.Contains(content, "`") {
+				fence = "``"
+				content = " " + content + " "
+			}
+			parent.writeRaw(fence + content + fence)
+		}
+
+	case "pre":
+		code := strings.Trim(frame.writer.b.String(), "\n")
+		if strings.TrimSpace(code) != "" {
+			code = strings.ReplaceAll(code, "```", "`\u200b``")
+			parent.writeBlock("```\n" + code + "\n```")
+		}
+
+	case "blockquote":
+		if content != "" {
+			lines := strings.Split(content, "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			parent.writeBlock(strings.Join(lines, "\n"))
+		}
+	}
+
+	if frame.writer.pendingSpace {
+		parent.pendingSpace = true
+	}
+}
+
+// listPrefix は、リスト項目の先頭に付ける記号（インデントを含む）を返す
+func (c *markdownConverter) listPrefix() string {
+	if len(c.lists) == 0 {
+		return "- "
+	}
+
+	indent := strings.Repeat("  ", len(c.lists)-1)
+	last := len(c.lists) - 1
+	if c.lists[last] > 0 {
+		prefix := indent + strconv.Itoa(c.lists[last]) + ". "
+		c.lists[last]++
+		return prefix
+	}
+	return indent + "- "
+}
+
+// blockBreak は、ブロック要素の区切りの改行数を返す（リスト内では空行を入れない）
+func (c *markdownConverter) blockBreak() int {
+	if len(c.lists) > 0 {
+		return 1
+	}
+	return 2
+}
+
+// resolveURL は、リンク先URLを絶対URLに解決する
+// javascript: などのhttp(s)以外のURLは空文字列を返す
+func (c *markdownConverter) resolveURL(href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" {
+		return ""
+	}
+
+	u, err := url.Parse(href)
+	if err != nil {
+		return ""
+	}
+	if !u.IsAbs() && c.baseURL != nil {
+		u = c.baseURL.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
+
+// EscapeLinkURL は、Markdownのリンク記法を壊す文字をURLエンコードする
+func EscapeLinkURL(u string) string {
+	return strings.NewReplacer("(", "%28", ")", "%29", " ", "%20").Replace(u)
+}
+
+// Attr は、HTMLトークンの属性値を返す（属性がない場合は空文字列）
+func Attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// isHeading は、見出し要素かどうかを返す
+func isHeading(tag string) bool {
+	return len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6'
+}
@@ -0,0 +1,162 @@
+package htmlconv
+
+import "testing"
+
+// TestToMarkdown は、HTMLからDiscordのMarkdownへの変換をテストする
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "リンク",
+			input: `Read <a href="https://example.com/post">the post</a> now`,
+			want:  "Read [the post](https://example.com/post) now",
+		},
+		{
+			name:  "相対URLのリンク",
+			input: `<a href="/docs/(v2)">docs</a>`,
+			want:  "[docs](https://example.com/docs/%28v2%29)",
+		},
+		{
+			name:  "URLと同じテキストのリンク",
+			input: `<a href="https://example.com/">https://example.com/</a>`,
+			want:  "https://example.com/",
+		},
+		{
+			name:  "javascriptのリンク",
+			input: `<a href="javascript:alert(1)">click</a>`,
+			want:  "click",
+		},
+		{
+			name:  "強調",
+			input: "<p><strong>Bold</strong>, <em>italic</em> and <b>b <i>both</i></b></p>",
+			want:  "**Bold**, *italic* and **b *both***",
+		},
+		{
+			name:  "空の強調と末尾の空白",
+			input: "a <strong></strong>b <strong>c </strong>d",
+			want:  "a b **c** d",
+		},
+		{
+			name:  "インラインコード",
+			input: "Use <code>go_test *</code> or <code>a`b</code>",
+			want:  "Use `go_test *` or `` a`b ``",
+		},
+		{
+			name:  "コードブロック",
+			input: "<p>Example:</p><pre><code>func main() {\n    fmt.Println(\"*\")\n}</code></pre>",
+			want:  "Example:\n\n```\nfunc main() {\n    fmt.Println(\"*\")\n}\n```",
+		},
+		{
+			name:  "箇条書き",
+			input: "<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>",
+			want:  "- one\n- two\n  - nested",
+		},
+		{
+			name:  "番号付きリスト",
+			input: "<p>Steps</p><ol><li><p>first</p></li><li>second</li></ol><p>Done</p>",
+			want:  "Steps\n\n1. first\n2. second\n\nDone",
+		},
+		{
+			name:  "見出しと引用",
+			input: "<h2>Title</h2><blockquote><p>quoted</p><p>text</p></blockquote>",
+			want:  "**Title**\n\n> quoted\n>\n> text",
+		},
+		{
+			name:  "Markdown記号のエスケープ",
+			input: "<p>2 * 3 = 6, snake_case, ~tilde~, [x], a|b, `tick`, # heading</p>",
+			want:  "2 \\* 3 = 6, snake\\_case, \\~tilde\\~, \\[x\\], a\\|b, \\`tick\\`, \\# heading",
+		},
+		{
+			name:  "URLはエスケープしない",
+			input: "see https://example.com/a_b_c",
+			want:  "see https://example.com/a_b_c",
+		},
+		{
+			name:  "本文以外の要素と文字参照",
+			input: "<script>var a = '<b>';</script><p>Tom &amp; Jerry</p><img src=x.png>",
+			want:  "Tom & Jerry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToMarkdown(tt.input, "https://example.com/blog/"); got != tt.want {
+				t.Errorf("ToMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEscapeMarkdown は、Markdown記号のエスケープをテストする
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain text", "plain text"},
+		{"**bold** _it_", `\*\*bold\*\* \_it\_`},
+		{"> quote", `\> quote`},
+		{"## title", `\## title`},
+		{"https://example.com/a_b", "https://example.com/a_b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := EscapeMarkdown(tt.input); got != tt.want {
+				t.Errorf("EscapeMarkdown(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEscapeLinkURL は、リンク記法を壊す文字のURLエンコードをテストする
+func TestEscapeLinkURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com/a_b", "https://example.com/a_b"},
+		{"https://example.com/Go_(language)", "https://example.com/Go_%28language%29"},
+		{"https://example.com/a b", "https://example.com/a%20b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := EscapeLinkURL(tt.input); got != tt.want {
+				t.Errorf("EscapeLinkURL(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEscapeMentions は、メンションの無効化をテストする
+func TestEscapeMentions(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"plain text", "plain text"},
+		{"hello @everyone", "hello @\u200beveryone"},
+		{"@here now", "@\u200bhere now"},
+		{"role <@&123>", "role <\u200b@&123>"},
+		{"user <@456> <@!789>", "user <\u200b@456> <\u200b@!789>"},
+		{"channel <#321>", "channel <\u200b#321>"},
+		{"mail user@example.com", "mail user@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := EscapeMentions(tt.input)
+			if got != tt.want {
+				t.Errorf("EscapeMentions(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			// 複数回適用しても結果は変わらない
+			if again := EscapeMentions(got); again != got {
+				t.Errorf("EscapeMentions() is not idempotent: %q", again)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package htmlconv は、フィードに含まれるHTMLを通知用のテキストに変換する
+package htmlconv
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ToText は、HTMLをプレーンテキストに変換する
+// HTMLトークナイザーで解析するため、属性値内の ">" や文字参照（&amp; &#39; &nbsp; など）も正しく扱える
+// script / style などの本文以外の要素は中身ごと除去し、段落の区切りは空行として残す
+func ToText(s string) string {
+	if !strings.ContainsAny(s, "<&") {
+		return strings.Join(strings.Fields(s), " ")
+	}
+
+	w := newTextWriter()
+	z := html.NewTokenizer(strings.NewReader(s))
+
+	skipDepth := 0
+	preDepth := 0
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			// io.EOF または不正なHTML。ここまでの内容を返す
+			return w.String()
+
+		case html.TextToken:
+			if skipDepth > 0 {
+				continue
+			}
+			text := string(z.Text())
+			if preDepth > 0 {
+				w.writePreformatted(text)
+			} else {
+				w.writeText(text)
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+
+			if skipElements[tag] {
+				if tt == html.StartTagToken {
+					skipDepth++
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			switch {
+			case tag == "br":
+				w.breakLine(1)
+			case paragraphElements[tag]:
+				w.breakLine(2)
+			case lineElements[tag]:
+				w.breakLine(1)
+			case tag == "td" || tag == "th":
+				w.pendingSpace = true
+			}
+
+			if tag == "pre" && tt == html.StartTagToken {
+				preDepth++
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+
+			if skipElements[tag] {
+				if skipDepth > 0 {
+					skipDepth--
+				}
+				continue
+			}
+			if skipDepth > 0 {
+				continue
+			}
+
+			switch {
+			case paragraphElements[tag]:
+				w.breakLine(2)
+			case lineElements[tag]:
+				w.breakLine(1)
+			}
+
+			if tag == "pre" && preDepth > 0 {
+				preDepth--
+			}
+		}
+	}
+}
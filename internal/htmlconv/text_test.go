@@ -0,0 +1,76 @@
+package htmlconv
+
+import "testing"
+
+// TestToText は、HTMLからプレーンテキストへの変換をテストする
+func TestToText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "プレーンテキスト",
+			input: "Plain   text\nwith newline",
+			want:  "Plain text with newline",
+		},
+		{
+			name:  "インライン要素",
+			input: "<p>Hello <strong>World</strong>, <em>again</em></p>",
+			want:  "Hello World, again",
+		},
+		{
+			name:  "段落と改行",
+			input: "<p>First<br>line</p>\n\n<p>Second</p><div>Third</div>",
+			want:  "First\nline\n\nSecond\n\nThird",
+		},
+		{
+			name:  "連続する段落区切りは空行1つ",
+			input: "<p>A</p><p></p><p></p><br><br><br><p>B</p>",
+			want:  "A\n\nB",
+		},
+		{
+			name:  "文字参照",
+			input: "&quot;Go&quot; &amp; &#x2764;&#xFE0F; &#12354;",
+			want:  "\"Go\" & ❤️ あ",
+		},
+		{
+			name:  "ノーブレークスペース",
+			input: "a&nbsp;&nbsp;b",
+			want:  "a b",
+		},
+		{
+			name:  "本文以外の要素",
+			input: "<head><title>T</title></head><noscript>enable js</noscript><p>Body</p><iframe src=x>frame</iframe>",
+			want:  "Body",
+		},
+		{
+			name:  "リスト",
+			input: "<ul><li>one</li><li>two</li></ul>",
+			want:  "one\ntwo",
+		},
+		{
+			name:  "整形済みテキスト",
+			input: "<p>Code:</p><pre>func main() {\n    fmt.Println()\n}</pre>",
+			want:  "Code:\n\nfunc main() {\n    fmt.Println()\n}",
+		},
+		{
+			name:  "日本語",
+			input: "<p>こんにちは、<b>世界</b>！</p><p>2段落目</p>",
+			want:  "こんにちは、世界！\n\n2段落目",
+		},
+		{
+			name:  "空文字列",
+			input: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ToText(tt.input); got != tt.want {
+				t.Errorf("ToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package htmlconv
+
+import "strings"
+
+// truncateEllipsis は、切り詰めた文字列の末尾に付ける省略記号
+const truncateEllipsis = "..."
+
+// emphasisMarkers は、切り詰める際に閉じる強調のMarkdown記号（長いものから順に照合する）
+var emphasisMarkers = []string{"**", "__", "~~", "*"}
+
+// markdownCut は、Markdownを切り詰められる位置
+type markdownCut struct {
+	// pos は切り詰める位置（rune単位）
+	pos int
+
+	// closing は、この位置で切り詰めた場合に閉じる必要がある記号
+	closing string
+
+	// boundary は、空白・改行の位置（単語の途中ではない）かどうか
+	boundary bool
+}
+
+// TruncateMarkdown は、DiscordのMarkdownを指定された文字数（rune単位、省略記号を含む）に切り詰める
+// リンク・インラインコード・エスケープの途中では切らず、空白・改行の位置を優先する
+// 開いたままのコードブロックと強調の記号は閉じる
+func TruncateMarkdown(s string, maxLength int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLength {
+		return s
+	}
+
+	limit := maxLength - len(truncateEllipsis)
+	if limit <= 0 {
+		return string(runes[:max(maxLength, 0)])
+	}
+
+	var boundary, word *markdownCut
+	cuts := scanMarkdownCuts(runes, limit)
+	for i := range cuts {
+		if cuts[i].pos+len([]rune(cuts[i].closing)) > limit {
+			continue
+		}
+		if cuts[i].boundary {
+			boundary = &cuts[i]
+		} else {
+			word = &cuts[i]
+		}
+	}
+
+	// 空白の位置が前半にしかない場合は、単語の途中で切り詰めてでも長く残す
+	best := boundary
+	if best == nil || (word != nil && best.pos < limit/2 && word.pos > best.pos) {
+		best = word
+	}
+	if best == nil {
+		return truncateEllipsis
+	}
+
+	text := strings.TrimRight(string(runes[:best.pos]), " \n")
+	return text + best.closing + truncateEllipsis
+}
+
+// scanMarkdownCuts は、limit までの切り詰められる位置を先頭から順に返す
+// リンク（[text](url)）とインラインコードは1つのまとまりとして扱い、途中の位置は返さない
+// コードブロック内は行末のみを返す
+func scanMarkdownCuts(runes []rune, limit int) []markdownCut {
+	cuts := make([]markdownCut, 0)
+	var markers []string
+	inFence := false
+	openedAt := -1
+
+	add := func(pos int, boundary bool) {
+		// 開いた直後の強調・コードブロックは閉じても空になるため、記号の前で切り詰める
+		if pos == openedAt {
+			return
+		}
+		closing := ""
+		for i := len(markers) - 1; i >= 0; i-- {
+			closing += markers[i]
+		}
+		if inFence {
+			closing += "\n```\n"
+		}
+		cuts = append(cuts, markdownCut{pos: pos, closing: closing, boundary: boundary})
+	}
+
+	for i := 0; i < len(runes) && i <= limit; {
+		// コードブロックの開始・終了（行頭の ```）
+		if (i == 0 || runes[i-1] == '\n') && hasRunesPrefix(runes[i:], "```") {
+			if !inFence {
+				add(i, true)
+			}
+			inFence = !inFence
+			i = lineEnd(runes, i)
+			if inFence {
+				openedAt = i
+			}
+			continue
+		}
+
+		if inFence {
+			if runes[i] == '\n' {
+				add(i, true)
+			}
+			i++
+			continue
+		}
+
+		switch r := runes[i]; {
+		case r == ' ' || r == '\n':
+			add(i, true)
+			i++
+
+		case r == '\\':
+			add(i, false)
+			i += 2
+
+		case r == '`':
+			add(i, false)
+			i = inlineCodeEnd(runes, i)
+
+		case r == '[':
+			add(i, false)
+			if end := linkEnd(runes, i); end > 0 {
+				i = end
+			} else {
+				i++
+			}
+
+		default:
+			marker := matchEmphasis(runes[i:], markers)
+			if marker == "" {
+				add(i, false)
+				i++
+				continue
+			}
+
+			add(i, false)
+			if len(markers) > 0 && markers[len(markers)-1] == marker {
+				markers = markers[:len(markers)-1]
+			} else {
+				markers = append(markers, marker)
+				openedAt = i + len(marker)
+			}
+			i += len(marker)
+		}
+	}
+
+	return cuts
+}
+
+// matchEmphasis は、先頭にある強調の記号を返す（閉じる記号を優先する）
+func matchEmphasis(runes []rune, markers []string) string {
+	if len(markers) > 0 && hasRunesPrefix(runes, markers[len(markers)-1]) {
+		return markers[len(markers)-1]
+	}
+	for _, marker := range emphasisMarkers {
+		if hasRunesPrefix(runes, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// inlineCodeEnd は、start から始まるインラインコードの直後の位置を返す
+// 閉じる記号がない場合は、記号を通常の文字として直後の位置を返す
+func inlineCodeEnd(runes []rune, start int) int {
+	n := 0
+	for start+n < len(runes) && runes[start+n] == '`' {
+		n++
+	}
+
+	fence := strings.Repeat("`", n)
+	for i := start + n; i+n <= len(runes); i++ {
+		if hasRunesPrefix(runes[i:], fence) {
+			return i + n
+		}
+	}
+	return start + n
+}
+
+// linkEnd は、start（[ の位置）から始まるリンク（[text](url)）の直後の位置を返す
+// リンクではない場合は -1 を返す
+func linkEnd(runes []rune, start int) int {
+	i := start + 1
+	for ; i < len(runes) && runes[i] != ']'; i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+		case '\n':
+			return -1
+		}
+	}
+	if i+1 >= len(runes) || runes[i+1] != '(' {
+		return -1
+	}
+
+	for i += 2; i < len(runes); i++ {
+		switch runes[i] {
+		case ')':
+			return i + 1
+		case ' ', '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// lineEnd は、start を含む行の改行の位置（最終行の場合は末尾）を返す
+func lineEnd(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == '\n' {
+			return i
+		}
+	}
+	return len(runes)
+}
+
+// hasRunesPrefix は、runes が prefix で始まるかどうかを返す
+func hasRunesPrefix(runes []rune, prefix string) bool {
+	p := []rune(prefix)
+	if len(runes) < len(p) {
+		return false
+	}
+	for i, r := range p {
+		if runes[i] != r {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package htmlconv
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestTruncateMarkdown は、Markdownの記法を壊さない切り詰めをテストする
+func TestTruncateMarkdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		maxLength int
+		want      string
+	}{
+		{
+			name:      "制限以内",
+			input:     "short [link](https://example.com/)",
+			maxLength: 100,
+			want:      "short [link](https://example.com/)",
+		},
+		{
+			name:      "単語の区切りで切り詰める",
+			input:     "The quick brown fox jumps over the lazy dog",
+			maxLength: 20,
+			want:      "The quick brown...",
+		},
+		{
+			name:      "リンクの途中では切らない",
+			input:     "Read [the full post](https://example.com/posts/very-long-article) today",
+			maxLength: 40,
+			want:      "Read...",
+		},
+		{
+			name:      "インラインコードの途中では切らない",
+			input:     "Run `go test ./internal/...` before pushing",
+			maxLength: 20,
+			want:      "Run...",
+		},
+		{
+			name:      "開いたままのコードブロックを閉じる",
+			input:     "Example:\n```\nline one\nline two\nline three\n```",
+			maxLength: 40,
+			want:      "Example:\n```\nline one\nline two\n```\n...",
+		},
+		{
+			name:      "開いたままの強調を閉じる",
+			input:     "This is **very important news for everyone** here",
+			maxLength: 30,
+			want:      "This is **very important**...",
+		},
+		{
+			name:      "エスケープを分割しない",
+			input:     `price\*\*\*\*\*\*\*\*\*\*`,
+			maxLength: 12,
+			want:      `price\*\*...`,
+		},
+		{
+			name:      "空白がない場合は単語の途中で切り詰める",
+			input:     "Supercalifragilisticexpialidocious",
+			maxLength: 10,
+			want:      "Superca...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateMarkdown(tt.input, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("TruncateMarkdown() = %q, want %q", got, tt.want)
+			}
+			if n := utf8.RuneCountInString(got); n > tt.maxLength {
+				t.Errorf("TruncateMarkdown() length = %d, want <= %d", n, tt.maxLength)
+			}
+			if strings.Count(got, "```")%2 != 0 {
+				t.Errorf("TruncateMarkdown() left an open code block: %q", got)
+			}
+		})
+	}
+}
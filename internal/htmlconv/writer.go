@@ -0,0 +1,220 @@
+package htmlconv
+
+import (
+	"strings"
+	"unicode"
+)
+
+// skipElements は、本文として扱わない要素（中身も含めて出力しない）
+var skipElements = map[string]bool{
+	"head":     true,
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"iframe":   true,
+	"object":   true,
+	"svg":      true,
+	"math":     true,
+	"button":   true,
+	"select":   true,
+	"form":     true,
+}
+
+// paragraphElements は、前後に空行を入れるブロック要素
+var paragraphElements = map[string]bool{
+	"p":          true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+	"blockquote": true,
+	"pre":        true,
+	"ul":         true,
+	"ol":         true,
+	"dl":         true,
+	"table":      true,
+	"figure":     true,
+	"hr":         true,
+	"section":    true,
+	"article":    true,
+	"aside":      true,
+	"header":     true,
+	"footer":     true,
+	"address":    true,
+}
+
+// lineElements は、前後で改行するブロック要素
+var lineElements = map[string]bool{
+	"div":        true,
+	"li":         true,
+	"tr":         true,
+	"dt":         true,
+	"dd":         true,
+	"figcaption": true,
+	"caption":    true,
+	"nav":        true,
+	"main":       true,
+}
+
+// textWriter は、空白と改行を正規化しながらテキストを組み立てる
+// 連続する空白は1つにまとめ、ブロック要素の区切りは改行（最大で空行1つ）にする
+type textWriter struct {
+	b strings.Builder
+
+	// pendingNewlines は次のテキストの前に出力する改行数
+	pendingNewlines int
+
+	// pendingSpace は次のテキストの前に空白を出力するかどうか
+	pendingSpace bool
+
+	// atLineStart は現在の出力位置が行頭かどうか
+	atLineStart bool
+
+	// pendingPrefix は次のテキストの直前に出力する文字列（Markdownの開始記号やリストの記号）
+	pendingPrefix string
+
+	// leadingSpace は最初のテキストより前に空白があったかどうか
+	leadingSpace bool
+}
+
+// newTextWriter は、新しいtextWriterを作成する
+func newTextWriter() *textWriter {
+	return &textWriter{atLineStart: true}
+}
+
+// breakLine は、次のテキストの前に n 個の改行を入れる（n は最大2）
+func (w *textWriter) breakLine(n int) {
+	if n > 2 {
+		n = 2
+	}
+	if n > w.pendingNewlines {
+		w.pendingNewlines = n
+	}
+	w.pendingSpace = false
+}
+
+// flush は、保留中の改行または空白を出力する
+func (w *textWriter) flush() {
+	if w.b.Len() == 0 {
+		w.pendingNewlines = 0
+		w.pendingSpace = false
+		return
+	}
+
+	if w.pendingNewlines > 0 {
+		w.b.WriteString(strings.Repeat("\n", w.pendingNewlines))
+		w.atLineStart = true
+	} else if w.pendingSpace && !w.atLineStart {
+		w.b.WriteByte(' ')
+	}
+
+	w.pendingNewlines = 0
+	w.pendingSpace = false
+}
+
+// writeText は、空白を正規化してテキストを出力する
+func (w *textWriter) writeText(text string) {
+	for _, word := range splitWords(text) {
+		if word == " " {
+			if w.b.Len() == 0 && w.pendingPrefix == "" {
+				w.leadingSpace = true
+			}
+			if w.pendingNewlines == 0 {
+				w.pendingSpace = true
+			}
+			continue
+		}
+		w.writeRaw(word)
+	}
+}
+
+// writePreformatted は、空白と改行をそのまま保持してテキストを出力する（pre要素用）
+func (w *textWriter) writePreformatted(text string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if i > 0 {
+			w.b.WriteByte('\n')
+			w.atLineStart = true
+		}
+		if line != "" {
+			w.writeRaw(line)
+		}
+	}
+}
+
+// writeRaw は、保留中の区切りを出力してから文字列をそのまま出力する
+func (w *textWriter) writeRaw(s string) {
+	if s == "" {
+		return
+	}
+	w.flush()
+	if w.pendingPrefix != "" {
+		w.b.WriteString(w.pendingPrefix)
+		w.pendingPrefix = ""
+	}
+	w.b.WriteString(s)
+	w.atLineStart = strings.HasSuffix(s, "\n")
+}
+
+// writeSuffix は、保留中の空白や改行より前に文字列を出力する（Markdownの終了記号用）
+// まだ何も出力していない場合は何もしない
+func (w *textWriter) writeSuffix(s string) {
+	if w.b.Len() == 0 {
+		return
+	}
+	w.b.WriteString(s)
+	w.atLineStart = false
+}
+
+// writeBlock は、前後に空行を入れて複数行の文字列をそのまま出力する
+func (w *textWriter) writeBlock(s string) {
+	if s == "" {
+		return
+	}
+	w.breakLine(2)
+	w.writeRaw(s)
+	w.breakLine(2)
+}
+
+// String は、組み立てたテキストを返す（各行末の空白と前後の空白は除去する）
+func (w *textWriter) String() string {
+	lines := strings.Split(w.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// splitWords は、テキストを単語と空白（" "）の並びに分割する
+// 連続する空白（改行・ノーブレークスペースを含む）は1つの " " にまとめる
+func splitWords(text string) []string {
+	words := make([]string, 0)
+	var current strings.Builder
+	inSpace := false
+
+	for _, r := range text {
+		if unicode.IsSpace(r) || r == ' ' {
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+			if !inSpace {
+				words = append(words, " ")
+				inSpace = true
+			}
+			continue
+		}
+		inSpace = false
+		current.WriteRune(r)
+	}
+
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+
+	return words
+}
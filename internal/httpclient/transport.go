@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// Transport は、フィード取得とDiscord通知で共有するHTTPトランスポート
+// プロキシ・CA証明書・クライアント証明書の設定と、コネクションプールを一元管理する
+type Transport struct {
+	// base は通常の通信に使用するトランスポート
+	base *http.Transport
+
+	// insecure はTLS証明書の検証を無効化したトランスポート（必要になった時点で作成）
+	insecure *http.Transport
+
+	// insecureOnce はinsecureの作成を1回に制限する
+	insecureOnce sync.Once
+}
+
+// NewTransport は、設定からHTTPトランスポートを作成する
+// cfg が nil の場合はデフォルト設定（環境変数のプロキシ設定とシステムのCA証明書）を使用する
+func NewTransport(cfg *models.HTTPConfig) (*Transport, error) {
+	if cfg == nil {
+		return NewDefaultTransport(), nil
+	}
+
+	base := newBaseTransport(cfg.MaxIdleConnsPerHost)
+
+	// プロキシ
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy_url: %q", cfg.ProxyURL)
+		}
+		base.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	// TLS設定
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	base.TLSClientConfig = tlsConfig
+
+	return &Transport{base: base}, nil
+}
+
+// NewDefaultTransport は、デフォルト設定のHTTPトランスポートを作成する
+func NewDefaultTransport() *Transport {
+	base := newBaseTransport(0)
+	base.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	return &Transport{base: base}
+}
+
+// newBaseTransport は、共通のコネクションプール設定を持つトランスポートを作成する
+func newBaseTransport(maxIdleConnsPerHost int) *http.Transport {
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = 10
+	}
+
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// newTLSConfig は、追加のCA証明書とクライアント証明書を含むTLS設定を作成する
+func newTLSConfig(cfg *models.HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	// 追加のCA証明書（システムのCA証明書に追加する）
+	if cfg.CACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		pem, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_cert_file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in ca_cert_file: %s", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// クライアント証明書
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, fmt.Errorf("client_cert_file and client_key_file must be specified together")
+		}
+
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// Client は、共有トランスポートを使用するHTTPクライアントを返す
+func (t *Transport) Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: t.base,
+		Timeout:   timeout,
+	}
+}
+
+// InsecureClient は、TLS証明書の検証を無効化したHTTPクライアントを返す
+// insecure_skip_verify が指定されたフィードのみで使用する
+// TLS設定が異なるため、コネクションプールは通常のクライアントとは別になる
+func (t *Transport) InsecureClient(timeout time.Duration) *http.Client {
+	t.insecureOnce.Do(func() {
+		t.insecure = t.base.Clone()
+		t.insecure.TLSClientConfig.InsecureSkipVerify = true
+	})
+
+	return &http.Client{
+		Transport: t.insecure,
+		Timeout:   timeout,
+	}
+}
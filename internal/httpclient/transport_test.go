@@ -0,0 +1,112 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestNewTransport は、設定からのトランスポート作成をテストする
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *models.HTTPConfig
+		wantErr bool
+	}{
+		{
+			name:    "設定なし",
+			cfg:     nil,
+			wantErr: false,
+		},
+		{
+			name:    "プロキシURL",
+			cfg:     &models.HTTPConfig{ProxyURL: "http://proxy.example.com:8080"},
+			wantErr: false,
+		},
+		{
+			name:    "無効なプロキシURL",
+			cfg:     &models.HTTPConfig{ProxyURL: "not a url"},
+			wantErr: true,
+		},
+		{
+			name:    "存在しないCA証明書",
+			cfg:     &models.HTTPConfig{CACertFile: "nonexistent.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "クライアント証明書の鍵がない",
+			cfg:     &models.HTTPConfig{ClientCertFile: "client.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := NewTransport(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTransport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && transport == nil {
+				t.Error("NewTransport() should not return nil")
+			}
+		})
+	}
+}
+
+// TestTransportCACert は、追加のCA証明書による検証をテストする
+func TestTransportCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// デフォルト設定では自己署名証明書を検証できない
+	if _, err := NewDefaultTransport().Client(5 * time.Second).Get(server.URL); err == nil {
+		t.Error("request with default transport should fail for self-signed certificate")
+	}
+
+	// サーバー証明書をCA証明書として追加すると成功する
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+
+	transport, err := NewTransport(&models.HTTPConfig{CACertFile: caFile})
+	if err != nil {
+		t.Fatalf("NewTransport() error = %v", err)
+	}
+
+	resp, err := transport.Client(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with CA certificate should succeed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+// TestInsecureClient は、TLS証明書の検証を無効化したクライアントをテストする
+func TestInsecureClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := NewDefaultTransport()
+
+	resp, err := transport.InsecureClient(5 * time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with insecure client should succeed: %v", err)
+	}
+	resp.Body.Close()
+
+	// 通常のクライアントの検証設定は変更されない
+	if _, err := transport.Client(5 * time.Second).Get(server.URL); err == nil {
+		t.Error("request with default client should still fail for self-signed certificate")
+	}
+}
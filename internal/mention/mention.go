@@ -0,0 +1,125 @@
+// Package mention は、フィード・カテゴリ・キーワードごとのメンションを記事に付与する
+package mention
+
+import (
+	"fmt"
+
+	"github.com/ken344/rss-discord-notifier/internal/filter"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// Mentioner は、設定に従って記事にメンションを付与する構造体
+type Mentioner struct {
+	// feeds はフィードURLごとのメンション（フィードの mentions、なければカテゴリの mentions）
+	feeds map[string][]*entry
+
+	// global はすべてのフィードの記事に適用するメンション（トップレベルの mentions）
+	global []*entry
+}
+
+// entry は、条件をコンパイル済みのメンションの設定
+type entry struct {
+	mentions []string
+	matcher  *filter.Matcher
+}
+
+// New は、設定からメンションの付与器を作成する
+// フィードの mentions はカテゴリの mentions より優先される
+func New(cfg *models.Config) (*Mentioner, error) {
+	m := &Mentioner{feeds: make(map[string][]*entry)}
+
+	global, err := compile(cfg.Mentions)
+	if err != nil {
+		return nil, err
+	}
+	m.global = global
+
+	categories := make(map[string][]*entry)
+	for name, category := range cfg.Categories {
+		if category == nil {
+			continue
+		}
+		entries, err := compile(category.Mentions)
+		if err != nil {
+			return nil, fmt.Errorf("category %s: %w", name, err)
+		}
+		categories[name] = entries
+	}
+
+	for _, fc := range cfg.Feeds {
+		if fc.Mentions == nil {
+			m.feeds[fc.URL] = categories[fc.Category]
+			continue
+		}
+		entries, err := compile(fc.Mentions)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", fc.Name, err)
+		}
+		m.feeds[fc.URL] = entries
+	}
+
+	return m, nil
+}
+
+// compile は、メンションの設定を検証し、条件をコンパイルする
+func compile(configs []*models.MentionConfig) ([]*entry, error) {
+	entries := make([]*entry, 0, len(configs))
+
+	for i, mc := range configs {
+		if mc == nil {
+			continue
+		}
+		if err := mc.Validate(); err != nil {
+			return nil, fmt.Errorf("mention %d: %w", i, err)
+		}
+
+		matcher, err := filter.NewMatcher(mc.Match)
+		if err != nil {
+			return nil, fmt.Errorf("mention %d: %w", i, err)
+		}
+
+		entries = append(entries, &entry{mentions: mc.Mentions(), matcher: matcher})
+	}
+
+	return entries, nil
+}
+
+// Mentions は、記事に付与するメンションを返す（重複は除く）
+func (m *Mentioner) Mentions(article *models.Article) []string {
+	var mentions []string
+
+	for _, entries := range [][]*entry{m.feeds[article.FeedURL], m.global} {
+		for _, e := range entries {
+			if e.matcher.Match(article) {
+				mentions = Merge(mentions, e.mentions)
+			}
+		}
+	}
+
+	return mentions
+}
+
+// Apply は、記事にメンションを付与する（既存のメンションは維持する）
+func (m *Mentioner) Apply(article *models.Article) {
+	if mentions := m.Mentions(article); len(mentions) > 0 {
+		article.Mentions = Merge(article.Mentions, mentions)
+	}
+}
+
+// Merge は、メンションのリストを順序を保ったまま重複なく連結する
+func Merge(lists ...[]string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+
+	for _, list := range lists {
+		for _, mention := range list {
+			if mention == "" || seen[mention] {
+				continue
+			}
+			seen[mention] = true
+			merged = append(merged, mention)
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,159 @@
+package mention
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// testConfig は、テスト用の設定（YAMLから読み込む）
+const testConfig = `
+mentions:
+  - roles: ["900"]
+    match:
+      - keywords: ["critical"]
+categories:
+  Security:
+    mentions:
+      - roles: [111111111111111111]
+  News:
+    mentions:
+      - here: true
+feeds:
+  - name: "Advisories"
+    url: "https://example.com/advisories"
+    category: "Security"
+  - name: "Release Notes"
+    url: "https://example.com/releases"
+    category: "Security"
+    mentions:
+      - users: ["222"]
+        match:
+          - patterns: ["(?i)breaking"]
+            fields: ["title"]
+  - name: "Quiet News"
+    url: "https://example.com/quiet"
+    category: "News"
+    mentions: []
+  - name: "Blog"
+    url: "https://example.com/blog"
+    category: "Blog"
+`
+
+// TestMentions は、フィード・カテゴリ・キーワードごとのメンションをテストする
+func TestMentions(t *testing.T) {
+	var cfg models.Config
+	if err := yaml.Unmarshal([]byte(testConfig), &cfg); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+
+	m, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		article *models.Article
+		want    []string
+	}{
+		{
+			name:    "カテゴリのメンション",
+			article: &models.Article{Title: "Advisory", FeedURL: "https://example.com/advisories"},
+			want:    []string{"<@&111111111111111111>"},
+		},
+		{
+			name:    "フィードのメンションがカテゴリより優先（条件に一致）",
+			article: &models.Article{Title: "Breaking change in v2", FeedURL: "https://example.com/releases"},
+			want:    []string{"<@222>"},
+		},
+		{
+			name:    "フィードのメンションの条件に一致しない",
+			article: &models.Article{Title: "v2.1 released", FeedURL: "https://example.com/releases"},
+			want:    nil,
+		},
+		{
+			name:    "空のリストでカテゴリのメンションを無効化",
+			article: &models.Article{Title: "News", FeedURL: "https://example.com/quiet"},
+			want:    nil,
+		},
+		{
+			name:    "キーワードに一致したメンションを追加",
+			article: &models.Article{Title: "Critical update", FeedURL: "https://example.com/advisories"},
+			want:    []string{"<@&111111111111111111>", "<@&900>"},
+		},
+		{
+			name:    "メンションのないフィード",
+			article: &models.Article{Title: "Post", FeedURL: "https://example.com/blog"},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Mentions(tt.article); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApply は、既存のメンションを維持したメンションの付与をテストする
+func TestApply(t *testing.T) {
+	cfg := &models.Config{
+		Feeds: []*models.FeedConfig{
+			{
+				Name:     "Advisories",
+				URL:      "https://example.com/advisories",
+				Mentions: []*models.MentionConfig{{Here: true, Roles: []string{"100"}}},
+			},
+		},
+	}
+
+	m, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	article := &models.Article{FeedURL: "https://example.com/advisories", Mentions: []string{"<@&100>"}}
+	m.Apply(article)
+
+	want := []string{"<@&100>", "@here"}
+	if !reflect.DeepEqual(article.Mentions, want) {
+		t.Errorf("Mentions = %v, want %v", article.Mentions, want)
+	}
+}
+
+// TestNewInvalid は、無効なメンションの設定でエラーになることをテストする
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		mention *models.MentionConfig
+	}{
+		{"メンション先がない", &models.MentionConfig{}},
+		{"無効なロールID", &models.MentionConfig{Roles: []string{"<@&100>"}}},
+		{"無効なユーザーID", &models.MentionConfig{Users: []string{"alice"}}},
+		{"無効な条件", &models.MentionConfig{Here: true, Match: []*models.FilterRule{{Patterns: []string{"("}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := map[string]*models.Config{
+				"mentions": {Mentions: []*models.MentionConfig{tt.mention}},
+				"categories": {Categories: map[string]*models.CategoryConfig{
+					"Security": {Mentions: []*models.MentionConfig{tt.mention}},
+				}},
+				"feeds": {Feeds: []*models.FeedConfig{
+					{Name: "Advisories", URL: "https://example.com/advisories", Mentions: []*models.MentionConfig{tt.mention}},
+				}},
+			}
+			for where, cfg := range configs {
+				if _, err := New(cfg); err == nil {
+					t.Errorf("New() should return error for invalid mention in %s", where)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package quota は、1回の実行で通知する記事数の上限をフィードやカテゴリに配分する
+package quota
+
+import (
+	"sort"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// Allocator は、通知する記事数の上限を配分する構造体
+type Allocator struct {
+	// mode は配分方式（newest, feed, category）
+	mode string
+
+	// priorities はフィードURLごとの優先度（重み）
+	priorities map[string]int
+}
+
+// group は、配分の単位（フィードまたはカテゴリ）ごとの記事
+type group struct {
+	key      string
+	weight   int
+	articles []*models.Article // 新しい順
+}
+
+// New は、配分方式とフィード設定からアロケーターを作成する
+func New(mode string, feedConfigs []*models.FeedConfig) *Allocator {
+	priorities := make(map[string]int, len(feedConfigs))
+	for _, fc := range feedConfigs {
+		priorities[fc.URL] = fc.GetPriority()
+	}
+
+	return &Allocator{mode: mode, priorities: priorities}
+}
+
+// Allocate は、上限の範囲で通知する記事を選び、通知する記事と次回に延期する記事を返す
+//
+// newest（デフォルト）の場合は、フィードを問わず新しい順に選ぶ。
+// feed / category の場合は、フィードまたはカテゴリごとに新しい順に並べ、
+// 優先度（重み）の件数ずつ順番に選ぶ（重み付きラウンドロビン）。
+func (a *Allocator) Allocate(articles []*models.Article, limit int) ([]*models.Article, []*models.Article) {
+	if len(articles) <= limit {
+		return articles, nil
+	}
+
+	sorted := sortNewestFirst(articles)
+
+	var selected []*models.Article
+	switch a.mode {
+	case models.QuotaModeFeed, models.QuotaModeCategory:
+		selected = a.roundRobin(sorted, limit)
+	default:
+		selected = sorted[:limit]
+	}
+
+	// 選ばれなかった記事を延期する（新しい順）
+	chosen := make(map[*models.Article]bool, len(selected))
+	for _, article := range selected {
+		chosen[article] = true
+	}
+	deferred := make([]*models.Article, 0, len(articles)-len(selected))
+	for _, article := range sorted {
+		if !chosen[article] {
+			deferred = append(deferred, article)
+		}
+	}
+
+	return selected, deferred
+}
+
+// roundRobin は、グループごとに重みの件数ずつ順番に記事を選ぶ
+func (a *Allocator) roundRobin(sorted []*models.Article, limit int) []*models.Article {
+	groups := a.groupArticles(sorted)
+	selected := make([]*models.Article, 0, limit)
+
+	for len(selected) < limit {
+		progressed := false
+
+		for _, g := range groups {
+			take := g.weight
+			if take > len(g.articles) {
+				take = len(g.articles)
+			}
+			if take > limit-len(selected) {
+				take = limit - len(selected)
+			}
+			if take == 0 {
+				continue
+			}
+
+			selected = append(selected, g.articles[:take]...)
+			g.articles = g.articles[take:]
+			progressed = true
+		}
+
+		if !progressed {
+			break
+		}
+	}
+
+	return selected
+}
+
+// groupArticles は、記事をフィードまたはカテゴリごとにまとめる
+// グループは重みの大きい順（同じ場合は最新記事の新しい順）に並べる
+func (a *Allocator) groupArticles(sorted []*models.Article) []*group {
+	groups := make([]*group, 0)
+	byKey := make(map[string]*group)
+
+	for _, article := range sorted {
+		key := article.FeedURL
+		if a.mode == models.QuotaModeCategory {
+			key = article.Category
+		}
+
+		g, ok := byKey[key]
+		if !ok {
+			g = &group{key: key}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.articles = append(g.articles, article)
+
+		// カテゴリの重みは、所属するフィードの優先度の最大値
+		if weight := a.priority(article.FeedURL); weight > g.weight {
+			g.weight = weight
+		}
+	}
+
+	// 記事は新しい順に処理しているため、groups は最新記事の新しい順に並んでいる
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].weight > groups[j].weight
+	})
+
+	return groups
+}
+
+// priority は、フィードの優先度を返す（設定にないフィードは1）
+func (a *Allocator) priority(feedURL string) int {
+	if p, ok := a.priorities[feedURL]; ok && p > 0 {
+		return p
+	}
+	return 1
+}
+
+// sortNewestFirst は、記事を公開日時の新しい順に並べたコピーを返す
+func sortNewestFirst(articles []*models.Article) []*models.Article {
+	sorted := make([]*models.Article, len(articles))
+	copy(sorted, articles)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PublishedAt.After(sorted[j].PublishedAt)
+	})
+	return sorted
+}
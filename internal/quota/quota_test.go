@@ -0,0 +1,132 @@
+package quota
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// newArticles は、指定したフィードの記事を新しい順に作成する
+func newArticles(feedURL, category string, count int, base time.Time) []*models.Article {
+	articles := make([]*models.Article, 0, count)
+	for i := 0; i < count; i++ {
+		articles = append(articles, &models.Article{
+			ID:          fmt.Sprintf("%s-%d", feedURL, i),
+			FeedURL:     feedURL,
+			Category:    category,
+			PublishedAt: base.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return articles
+}
+
+// countByFeed は、フィードごとの記事数を返す
+func countByFeed(articles []*models.Article) map[string]int {
+	counts := make(map[string]int)
+	for _, article := range articles {
+		counts[article.FeedURL]++
+	}
+	return counts
+}
+
+// TestAllocate は、配分方式ごとの記事の選択をテストする
+func TestAllocate(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+
+	// 多作なフィード（新しい記事が多い）と静かなフィード
+	var articles []*models.Article
+	articles = append(articles, newArticles("busy", "News", 20, now)...)
+	articles = append(articles, newArticles("quiet-a", "Tech", 2, now.Add(-time.Hour))...)
+	articles = append(articles, newArticles("quiet-b", "Tech", 2, now.Add(-2*time.Hour))...)
+
+	feeds := []*models.FeedConfig{
+		{Name: "Busy", URL: "busy"},
+		{Name: "Quiet A", URL: "quiet-a"},
+		{Name: "Quiet B", URL: "quiet-b", Priority: 2},
+	}
+
+	tests := []struct {
+		name string
+		mode string
+		want map[string]int
+	}{
+		{"新しい順", models.QuotaModeNewest, map[string]int{"busy": 6}},
+		{"フィードごと（優先度で重み付け）", models.QuotaModeFeed, map[string]int{"busy": 2, "quiet-a": 2, "quiet-b": 2}},
+		// Tech の重みは所属フィードの優先度の最大値（2）
+		{"カテゴリごと", models.QuotaModeCategory, map[string]int{"busy": 2, "quiet-a": 2, "quiet-b": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocator := New(tt.mode, feeds)
+			selected, deferred := allocator.Allocate(articles, 6)
+
+			if len(selected) != 6 {
+				t.Fatalf("selected = %d, want 6", len(selected))
+			}
+			if len(deferred) != len(articles)-6 {
+				t.Errorf("deferred = %d, want %d", len(deferred), len(articles)-6)
+			}
+
+			got := countByFeed(selected)
+			for feedURL, want := range tt.want {
+				if got[feedURL] != want {
+					t.Errorf("selected[%s] = %d, want %d (all: %v)", feedURL, got[feedURL], want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestAllocateNewestInGroup は、グループ内では新しい記事から選ばれることをテストする
+func TestAllocateNewestInGroup(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+	articles := newArticles("feed", "Tech", 5, now)
+
+	// 古い順に並べ替えて渡す
+	reversed := make([]*models.Article, 0, len(articles))
+	for i := len(articles) - 1; i >= 0; i-- {
+		reversed = append(reversed, articles[i])
+	}
+
+	selected, deferred := New(models.QuotaModeFeed, nil).Allocate(reversed, 2)
+	if selected[0] != articles[0] || selected[1] != articles[1] {
+		t.Error("newest articles should be selected")
+	}
+	if len(deferred) != 3 || deferred[0] != articles[2] {
+		t.Error("remaining articles should be deferred newest first")
+	}
+}
+
+// TestAllocateUnderLimit は、上限以下の場合はすべて選ばれることをテストする
+func TestAllocateUnderLimit(t *testing.T) {
+	articles := newArticles("feed", "Tech", 3, time.Now())
+
+	selected, deferred := New(models.QuotaModeFeed, nil).Allocate(articles, 10)
+	if len(selected) != 3 || len(deferred) != 0 {
+		t.Errorf("selected = %d, deferred = %d, want 3, 0", len(selected), len(deferred))
+	}
+}
+
+// TestAllocateCategoryLimit は、カテゴリごとの配分で1巡の途中で上限に達する場合をテストする
+func TestAllocateCategoryLimit(t *testing.T) {
+	now := time.Date(2025, 11, 13, 12, 0, 0, 0, time.UTC)
+
+	var articles []*models.Article
+	articles = append(articles, newArticles("news-1", "News", 10, now)...)
+	articles = append(articles, newArticles("news-2", "News", 10, now)...)
+	articles = append(articles, newArticles("tech", "Tech", 10, now.Add(-time.Hour))...)
+
+	selected, _ := New(models.QuotaModeCategory, nil).Allocate(articles, 5)
+
+	// News は2フィードあってもカテゴリとしては1枠
+	got := countByFeed(selected)
+	if got["tech"] != 2 {
+		t.Errorf("selected[tech] = %d, want 2 (all: %v)", got["tech"], got)
+	}
+	if got["news-1"]+got["news-2"] != 3 {
+		t.Errorf("selected[News] = %d, want 3 (all: %v)", got["news-1"]+got["news-2"], got)
+	}
+}
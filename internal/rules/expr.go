@@ -0,0 +1,396 @@
+// Package rules は、記事の内容に応じて通知先を決定するルーティングルールを扱う
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// 条件式で使用できるフィールド
+var fields = map[string]func(a *models.Article) []string{
+	"title":       func(a *models.Article) []string { return []string{a.Title} },
+	"description": func(a *models.Article) []string { return []string{a.Description} },
+	"content":     func(a *models.Article) []string { return []string{a.Content} },
+	"author":      func(a *models.Article) []string { return []string{a.Author} },
+	"url":         func(a *models.Article) []string { return []string{a.URL} },
+	"category":    func(a *models.Article) []string { return []string{a.Category} },
+	"feed":        func(a *models.Article) []string { return []string{a.FeedName} },
+	"feed_url":    func(a *models.Article) []string { return []string{a.FeedURL} },
+	"tags":        func(a *models.Article) []string { return a.Tags },
+}
+
+// Expr は、パース済みの条件式
+//
+// 構文:
+//
+//	expr       = or
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | primary
+//	primary    = "(" expr ")" | "true" | "false" | comparison
+//	comparison = field ( "==" | "!=" | "contains" | "matches" ) string
+//
+// field は title, description, content, author, url, category, feed, feed_url, tags のいずれか。
+// contains は大文字小文字を区別しない部分一致、matches は正規表現（Goの regexp 構文）。
+// tags のように複数の値を持つフィールドは、いずれかの値が一致すれば真（!= はいずれも一致しない場合に真）。
+type Expr struct {
+	root node
+}
+
+// Parse は、条件式をパースする
+// 空文字列はすべての記事に一致する条件式として扱う
+func Parse(s string) (*Expr, error) {
+	if strings.TrimSpace(s) == "" {
+		return &Expr{root: boolNode(true)}, nil
+	}
+
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+
+	return &Expr{root: root}, nil
+}
+
+// Eval は、記事が条件式に一致するかを判定する
+func (e *Expr) Eval(article *models.Article) bool {
+	return e.root.eval(article)
+}
+
+// --- 字句解析 ---
+
+// tokenKind は、トークンの種類
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenEq
+	tokenNeq
+)
+
+// token は、条件式のトークン
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// String は、エラーメッセージ用のトークン表現を返す
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.value)
+	default:
+		return fmt.Sprintf("%q", t.value)
+	}
+}
+
+// tokenize は、条件式をトークンに分割する
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+
+		case r == '&' || r == '|' || r == '=':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+			}
+			kind := map[rune]tokenKind{'&': tokenAnd, '|': tokenOr, '=': tokenEq}[r]
+			tokens = append(tokens, token{kind: kind, value: string([]rune{r, r}), pos: i})
+			i += 2
+
+		case r == '!':
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				tokens = append(tokens, token{kind: tokenNeq, value: "!=", pos: i})
+				i += 2
+			} else {
+				tokens = append(tokens, token{kind: tokenNot, value: "!", pos: i})
+				i++
+			}
+
+		case r == '"' || r == '`':
+			value, next, err := scanString(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: i})
+			i = next
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[start:i]), pos: start})
+
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", r, i)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// scanString は、文字列リテラルを読み取り、値と次の位置を返す
+// ダブルクォートはGoの文字列リテラルと同じエスケープ、バッククォートはエスケープなし（正規表現向け）
+func scanString(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if quote == '"' {
+				i++ // エスケープされた文字を読み飛ばす
+			}
+		case quote:
+			literal := string(runes[start : i+1])
+			value, err := strconv.Unquote(literal)
+			if err != nil {
+				return "", 0, fmt.Errorf("invalid string %s at position %d: %w", literal, start, err)
+			}
+			return value, i + 1, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+// --- 構文解析 ---
+
+// parser は、トークン列から構文木を作成する再帰下降パーサー
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// peek は、次のトークンを返す（読み進めない）
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// next は、次のトークンを返して読み進める
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr は、|| で連結された式をパースする
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseAnd は、&& で連結された式をパースする
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// parseUnary は、否定をパースする
+func (p *parser) parseUnary() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{x: x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary は、括弧・真偽値・比較をパースする
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenLParen:
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected \")\" at position %d, got %s", closing.pos, closing)
+		}
+		return x, nil
+
+	case tokenIdent:
+		switch tok.value {
+		case "true":
+			return boolNode(true), nil
+		case "false":
+			return boolNode(false), nil
+		}
+		return p.parseComparison(tok)
+
+	default:
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+}
+
+// parseComparison は、フィールドと文字列の比較をパースする
+func (p *parser) parseComparison(field token) (node, error) {
+	getter, ok := fields[field.value]
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q at position %d", field.value, field.pos)
+	}
+
+	op := p.next()
+	switch {
+	case op.kind == tokenEq, op.kind == tokenNeq:
+	case op.kind == tokenIdent && (op.value == "contains" || op.value == "matches"):
+	default:
+		return nil, fmt.Errorf("expected operator after %q at position %d, got %s", field.value, op.pos, op)
+	}
+
+	value := p.next()
+	if value.kind != tokenString {
+		return nil, fmt.Errorf("expected string after %q at position %d, got %s", op.value, value.pos, value)
+	}
+
+	n := &compareNode{field: getter, op: op.value, value: value.value}
+	switch op.value {
+	case "contains":
+		n.value = strings.ToLower(value.value)
+	case "matches":
+		re, err := regexp.Compile(value.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp at position %d: %w", value.pos, err)
+		}
+		n.re = re
+	}
+
+	return n, nil
+}
+
+// --- 評価 ---
+
+// node は、条件式の構文木のノード
+type node interface {
+	eval(article *models.Article) bool
+}
+
+// boolNode は、true / false のリテラル
+type boolNode bool
+
+func (n boolNode) eval(*models.Article) bool { return bool(n) }
+
+// andNode は、論理積
+type andNode struct{ left, right node }
+
+func (n *andNode) eval(a *models.Article) bool { return n.left.eval(a) && n.right.eval(a) }
+
+// orNode は、論理和
+type orNode struct{ left, right node }
+
+func (n *orNode) eval(a *models.Article) bool { return n.left.eval(a) || n.right.eval(a) }
+
+// notNode は、否定
+type notNode struct{ x node }
+
+func (n *notNode) eval(a *models.Article) bool { return !n.x.eval(a) }
+
+// compareNode は、フィールドと文字列の比較
+type compareNode struct {
+	field func(a *models.Article) []string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+func (n *compareNode) eval(a *models.Article) bool {
+	if n.op == "!=" {
+		for _, v := range n.field(a) {
+			if v == n.value {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, v := range n.field(a) {
+		switch n.op {
+		case "==":
+			if v == n.value {
+				return true
+			}
+		case "contains":
+			if strings.Contains(strings.ToLower(v), n.value) {
+				return true
+			}
+		case "matches":
+			if n.re.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
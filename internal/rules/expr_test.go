@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// TestEval は、条件式の評価をテストする
+func TestEval(t *testing.T) {
+	article := &models.Article{
+		Title:       "Kubernetes 1.30 Released",
+		Description: "New features for Pods",
+		Author:      "k8s-release",
+		URL:         "https://kubernetes.io/blog/1.30",
+		Category:    "Tech",
+		FeedName:    "Kubernetes Blog",
+		FeedURL:     "https://kubernetes.io/feed.xml",
+		Tags:        []string{"release", "security"},
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want bool
+	}{
+		{"空の条件式", "", true},
+		{"等価", `category == "Tech"`, true},
+		{"不等価", `category != "Tech"`, false},
+		{"正規表現", `title matches "(?i)kubernetes"`, true},
+		{"バッククォートの正規表現", "title matches `\\d+\\.\\d+`", true},
+		{"部分一致は大文字小文字を区別しない", `description contains "pods"`, true},
+		{"論理積", `category == "Tech" && title matches "(?i)kubernetes"`, true},
+		{"論理積（偽）", `category == "News" && title matches "(?i)kubernetes"`, false},
+		{"論理和", `category == "News" || feed == "Kubernetes Blog"`, true},
+		{"否定", `!(author contains "bot")`, true},
+		{"優先順位（&& が || より先）", `false && true || true`, true},
+		{"括弧", `false && (true || true)`, false},
+		{"複数値フィールド", `tags == "security"`, true},
+		{"複数値フィールドの不等価", `tags != "security"`, false},
+		{"エスケープ", `title != "\"quoted\""`, true},
+		{"日本語", `title contains "リリース"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			if got := expr.Eval(article); got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseError は、不正な条件式がエラーになることをテストする
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"不明なフィールド", `body == "x"`},
+		{"演算子なし", `title "x"`},
+		{"値が文字列でない", `title == Tech`},
+		{"閉じ括弧なし", `(title == "x"`},
+		{"余分なトークン", `title == "x" "y"`},
+		{"単独の&", `title == "x" & true`},
+		{"単独の=", `title = "x"`},
+		{"閉じていない文字列", `title == "x`},
+		{"無効な正規表現", `title matches "("`},
+		{"右辺なし", `title == "x" &&`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.expr); err == nil {
+				t.Errorf("Parse(%q) should return error", tt.expr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+// Router は、ルーティングルールを記事に適用して通知先を決定する構造体
+type Router struct {
+	// rules はコンパイル済みのルール（設定順）
+	rules []*compiledRule
+}
+
+// compiledRule は、条件式をパース済みのルール
+type compiledRule struct {
+	rule *models.RoutingRule
+	when *Expr
+}
+
+// New は、ルーティングルールからルーターを作成する
+func New(rules []*models.RoutingRule) (*Router, error) {
+	r := &Router{rules: make([]*compiledRule, 0, len(rules))}
+
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i, rule.DisplayName(), err)
+		}
+
+		when, err := Parse(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%s): invalid condition: %w", i, rule.DisplayName(), err)
+		}
+
+		r.rules = append(r.rules, &compiledRule{rule: rule, when: when})
+	}
+
+	return r, nil
+}
+
+// Route は、記事にルールを適用して通知先（article.Routes）を設定する
+// 記事を破棄するルールに一致した場合は false を返す
+//
+// ルールは上から順に評価し、一致したすべてのルールを適用する（stop: true のルールに一致した時点で終了）。
+// webhook_url を持つルールが1つも一致しなかった場合は、記事の本来の通知先に通知する。
+func (r *Router) Route(article *models.Article) bool {
+	var routes []*models.Route
+	var mentions []string
+
+	for _, c := range r.rules {
+		if !c.when.Eval(article) {
+			continue
+		}
+
+		if c.rule.Drop {
+			logger.Debug("ルールにより記事を破棄",
+				"rule", c.rule.DisplayName(),
+				"title", article.Title,
+				"feed", article.FeedName)
+			return false
+		}
+
+		if c.rule.WebhookURL != "" {
+			route := findRoute(routes, c.rule.WebhookURL)
+			if route == nil {
+				route = &models.Route{WebhookURL: c.rule.WebhookURL}
+				routes = append(routes, route)
+			}
+			route.Mentions = appendUnique(route.Mentions, c.rule.Mention)
+		} else {
+			mentions = appendUnique(mentions, c.rule.Mention)
+		}
+
+		if c.rule.Stop {
+			break
+		}
+	}
+
+	// 通知先の指定がない場合は本来の通知先
+	if len(routes) == 0 && len(mentions) > 0 {
+		routes = []*models.Route{{}}
+	}
+
+	// 通知先を問わないメンションはすべての通知先に付与する
+	for _, route := range routes {
+		for _, mention := range mentions {
+			route.Mentions = appendUnique(route.Mentions, mention)
+		}
+	}
+
+	article.Routes = routes
+	return true
+}
+
+// findRoute は、指定されたWebhook URLの通知先を返す
+func findRoute(routes []*models.Route, webhookURL string) *models.Route {
+	for _, route := range routes {
+		if route.WebhookURL == webhookURL {
+			return route
+		}
+	}
+	return nil
+}
+
+// appendUnique は、空でなく重複しない場合のみ値を追加する
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
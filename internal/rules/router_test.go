@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ken344/rss-discord-notifier/internal/logger"
+	"github.com/ken344/rss-discord-notifier/pkg/models"
+)
+
+func init() {
+	// テスト用のロガーを初期化（出力を抑制）
+	logger.Init(&logger.Config{
+		Level:  logger.LevelError,
+		Format: logger.FormatJSON,
+		Output: os.Stderr,
+	})
+}
+
+// TestRoute は、ルーティングルールの適用をテストする
+func TestRoute(t *testing.T) {
+	ruleSet := []*models.RoutingRule{
+		{Name: "sponsored", When: `title contains "sponsored"`, Drop: true},
+		{Name: "k8s", When: `title matches "(?i)kubernetes"`, WebhookURL: "https://discord.com/api/webhooks/k8s"},
+		{Name: "security", When: `tags == "security"`, WebhookURL: "https://discord.com/api/webhooks/security", Mention: "<@&100>"},
+		{Name: "oncall", When: `tags == "cve"`, Mention: "<@&200>"},
+		{Name: "news-only", When: `category == "News"`, Stop: true},
+		{Name: "after-stop", When: `category == "News"`, WebhookURL: "https://discord.com/api/webhooks/news"},
+	}
+
+	router, err := New(ruleSet)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		article    *models.Article
+		wantKeep   bool
+		wantRoutes []*models.Route
+	}{
+		{
+			name:     "一致なし",
+			article:  &models.Article{Title: "Go 1.22", Category: "Tech"},
+			wantKeep: true,
+		},
+		{
+			name:     "破棄",
+			article:  &models.Article{Title: "Sponsored: Kubernetes hosting"},
+			wantKeep: false,
+		},
+		{
+			name:     "複数の通知先に振り分け",
+			article:  &models.Article{Title: "Kubernetes CVE", Tags: []string{"security", "cve"}},
+			wantKeep: true,
+			wantRoutes: []*models.Route{
+				{WebhookURL: "https://discord.com/api/webhooks/k8s", Mentions: []string{"<@&200>"}},
+				{WebhookURL: "https://discord.com/api/webhooks/security", Mentions: []string{"<@&100>", "<@&200>"}},
+			},
+		},
+		{
+			name:       "メンションのみ（本来の通知先）",
+			article:    &models.Article{Title: "OpenSSL advisory", Tags: []string{"cve"}},
+			wantKeep:   true,
+			wantRoutes: []*models.Route{{Mentions: []string{"<@&200>"}}},
+		},
+		{
+			name:     "stopで以降のルールを評価しない",
+			article:  &models.Article{Title: "Daily news", Category: "News"},
+			wantKeep: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := router.Route(tt.article); got != tt.wantKeep {
+				t.Fatalf("Route() = %v, want %v", got, tt.wantKeep)
+			}
+			if !tt.wantKeep {
+				return
+			}
+			if !reflect.DeepEqual(tt.article.Routes, tt.wantRoutes) {
+				t.Errorf("Routes = %+v, want %+v", tt.article.Routes, tt.wantRoutes)
+			}
+		})
+	}
+}
+
+// TestNewInvalidRule は、無効なルールでエラーになることをテストする
+func TestNewInvalidRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule *models.RoutingRule
+	}{
+		{"アクションなし", &models.RoutingRule{When: `category == "Tech"`}},
+		{"dropと通知先の併用", &models.RoutingRule{Drop: true, WebhookURL: "https://discord.com/api/webhooks/x"}},
+		{"構文エラー", &models.RoutingRule{When: `category ==`, Drop: true}},
+		{"末尾の演算子", &models.RoutingRule{When: `category == "Tech" &&`, WebhookURL: "https://discord.com/api/webhooks/tech"}},
+		{"ロール名のメンション", &models.RoutingRule{Mention: "@oncall"}},
+		{"IDが数字ではないメンション", &models.RoutingRule{Mention: "<@&oncall>"}},
+		{"複数のメンション", &models.RoutingRule{Mention: "<@&100> <@&200>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]*models.RoutingRule{tt.rule}); err == nil {
+				t.Error("New() should return error for invalid rule")
+			}
+		})
+	}
+}
@@ -100,7 +100,12 @@ func (m *Manager) Save() error {
 }
 
 // IsArticleNotified は、指定された記事が通知済みかチェックする
+// ダイジェストの待ちに含まれている記事も通知済みとして扱う
 func (m *Manager) IsArticleNotified(feedURL, articleID string) bool {
+	if m.state.IsArticleQueued(feedURL, articleID) {
+		return true
+	}
+
 	// フィードが存在しない場合はfalseを返す（新規作成しない）
 	feedState, exists := m.state.Feeds[feedURL]
 	if !exists {
@@ -118,11 +123,126 @@ func (m *Manager) MarkAsNotified(article *models.Article) {
 	m.state.Statistics.TotalArticlesNotified++
 }
 
+// IsRouteDelivered は、指定された記事を指定された通知先（routeKey）に通知済みかチェックする
+// 複数の通知先がある記事で、前回の実行で通知に成功した通知先に再度通知しないために使用する
+func (m *Manager) IsRouteDelivered(feedURL, articleID, routeKey string) bool {
+	feedState, exists := m.state.Feeds[feedURL]
+	if !exists {
+		return false
+	}
+	return feedState.IsRouteDelivered(articleID, routeKey)
+}
+
+// MarkRouteDelivered は、記事を指定された通知先（routeKey）に通知したことを記録する
+// 記録は記事を通知済みとしてマークした時点で削除される
+func (m *Manager) MarkRouteDelivered(article *models.Article, routeKey string) {
+	feedState := m.state.GetFeedState(article.FeedURL)
+	feedState.AddDeliveredRoute(article.ID, routeKey)
+}
+
+// MarkAsSkipped は、記事を通知せずに既読としてマークする
+// フィルターで除外した記事が次回以降に再評価されないようにするために使用する
+func (m *Manager) MarkAsSkipped(article *models.Article) {
+	feedState := m.state.GetFeedState(article.FeedURL)
+	feedState.AddSkippedArticle(article)
+}
+
+// QueueForDigest は、記事をダイジェストの待ちに追加する
+// 追加した記事はダイジェストを通知するまで通知済みとして扱われる
+func (m *Manager) QueueForDigest(article *models.Article) {
+	if m.state.IsArticleQueued(article.FeedURL, article.ID) {
+		return
+	}
+
+	// フィードの状態を作成し、次回以降に初めて取得するフィードとして扱われないようにする
+	feedState := m.state.GetFeedState(article.FeedURL)
+	feedState.LastCheck = time.Now()
+
+	m.state.DigestQueue = append(m.state.DigestQueue, models.NewPendingDigestArticle(article, time.Now()))
+}
+
+// GetDigestQueue は、ダイジェストの待ちの記事を返す
+func (m *Manager) GetDigestQueue() []*models.PendingDigestArticle {
+	return m.state.DigestQueue
+}
+
+// MarkDigestAsNotified は、ダイジェストで通知した記事を待ちから削除し、通知済みとしてマークする
+func (m *Manager) MarkDigestAsNotified(article *models.Article) {
+	m.state.RemoveFromDigestQueue(article.FeedURL, article.ID)
+	m.MarkAsNotified(article)
+
+	feedState := m.state.GetFeedState(article.FeedURL)
+	feedState.NotifiedArticles[len(feedState.NotifiedArticles)-1].Digest = true
+}
+
+// GetNotifiedArticle は、指定された記事の通知済みの記録を返す（存在しない場合は nil）
+func (m *Manager) GetNotifiedArticle(feedURL, articleID string) *models.NotifiedArticle {
+	feedState, exists := m.state.Feeds[feedURL]
+	if !exists {
+		return nil
+	}
+	return feedState.FindArticle(articleID)
+}
+
+// UpdateNotifiedArticle は、通知済みの記事の記録を更新後の内容に更新する
+func (m *Manager) UpdateNotifiedArticle(article *models.Article) {
+	if notified := m.GetNotifiedArticle(article.FeedURL, article.ID); notified != nil {
+		notified.Update(article)
+	}
+}
+
+// MarkAsRetracted は、フィードから削除された記事の通知を取り下げたことを記録する
+func (m *Manager) MarkAsRetracted(feedURL, articleID string) {
+	if notified := m.GetNotifiedArticle(feedURL, articleID); notified != nil {
+		notified.Retracted = true
+	}
+}
+
+// IsMessageShared は、指定されたメッセージに複数の記事が含まれているかチェックする
+// （embeds_per_message で複数の記事をまとめて通知した場合など）
+func (m *Manager) IsMessageShared(messageID string) bool {
+	if messageID == "" {
+		return false
+	}
+
+	count := 0
+	for _, feedState := range m.state.Feeds {
+		for _, article := range feedState.NotifiedArticles {
+			if article.MessageID == messageID {
+				count++
+			}
+		}
+	}
+	return count > 1
+}
+
 // GetFeedState は、指定されたフィードの状態を取得する
 func (m *Manager) GetFeedState(feedURL string) *models.FeedState {
 	return m.state.GetFeedState(feedURL)
 }
 
+// GetCacheValidators は、フィードごとの条件付きGET用の検証子を返す
+func (m *Manager) GetCacheValidators() map[string]models.CacheValidators {
+	validators := make(map[string]models.CacheValidators)
+	for feedURL, feedState := range m.state.Feeds {
+		v := models.CacheValidators{
+			ETag:         feedState.ETag,
+			LastModified: feedState.LastModified,
+		}
+		if !v.IsEmpty() {
+			validators[feedURL] = v
+		}
+	}
+	return validators
+}
+
+// SetCacheValidators は、フィードの条件付きGET用の検証子を更新する
+func (m *Manager) SetCacheValidators(feedURL string, validators models.CacheValidators) {
+	feedState := m.state.GetFeedState(feedURL)
+	feedState.ETag = validators.ETag
+	feedState.LastModified = validators.LastModified
+}
+
 // UpdateStatistics は、統計情報を更新する
 func (m *Manager) UpdateStatistics(feedsChecked int, duration float64) {
 	m.state.Statistics.TotalFeedsChecked += feedsChecked
@@ -177,6 +297,13 @@ func (m *Manager) GetNotifiedArticleCount(feedURL string) int {
 	return len(feedState.NotifiedArticles)
 }
 
+// HasFeedState は、指定されたフィードの状態が存在するかチェックする
+// 状態が存在しないフィードは、初めて取得するフィードとして扱う
+func (m *Manager) HasFeedState(feedURL string) bool {
+	_, exists := m.state.Feeds[feedURL]
+	return exists
+}
+
 // IsFirstRun は、初回実行かどうかを判定する
 func (m *Manager) IsFirstRun() bool {
 	// 状態ファイルが存在せず、フィードが1つもない場合は初回実行
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -67,8 +68,14 @@ func TestLoadAndSave(t *testing.T) {
 	}
 
 	// ファイルが作成されたか確認
-	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
-		t.Fatal("state file should exist after Save()")
+	data, err := os.ReadFile(stateFile)
+	if err != nil {
+		t.Fatalf("state file should exist after Save(): %v", err)
+	}
+
+	// 更新日時のない記事は updated_at を記録しない
+	if strings.Contains(string(data), "updated_at") {
+		t.Error("updated_at should be omitted for an article without an update time")
 	}
 
 	// 新しいマネージャーで読み込み
@@ -121,6 +128,9 @@ func TestMarkAsNotified(t *testing.T) {
 		URL:         "https://example.com/article-1",
 		PublishedAt: time.Now(),
 		FeedURL:     "https://example.com/feed",
+		MessageID:   "1001",
+		ChannelID:   "2002",
+		WebhookID:   "3003",
 	}
 
 	// 初期の統計
@@ -139,9 +149,279 @@ func TestMarkAsNotified(t *testing.T) {
 	if !manager.IsArticleNotified("https://example.com/feed", "article-1") {
 		t.Error("article should be marked as notified")
 	}
+
+	// 投稿したメッセージのIDを記録
+	notified := manager.GetFeedState("https://example.com/feed").NotifiedArticles[0]
+	if notified.MessageID != "1001" || notified.ChannelID != "2002" || notified.WebhookID != "3003" {
+		t.Errorf("MessageID = %q, ChannelID = %q, WebhookID = %q, want 1001, 2002, 3003",
+			notified.MessageID, notified.ChannelID, notified.WebhookID)
+	}
+}
+
+// TestMarkAsSkipped は、通知せずに既読とするマーキングをテストする
+func TestMarkAsSkipped(t *testing.T) {
+	manager := NewManager("test.json")
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Filtered Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedURL:     "https://example.com/feed",
+	}
+	manager.MarkAsSkipped(article)
+
+	// 既読として扱われる
+	if !manager.IsArticleNotified("https://example.com/feed", "article-1") {
+		t.Error("skipped article should be treated as notified")
+	}
+
+	// 通知数には含めない
+	if manager.state.Statistics.TotalArticlesNotified != 0 {
+		t.Errorf("TotalArticlesNotified = %d, want 0", manager.state.Statistics.TotalArticlesNotified)
+	}
+
+	if !manager.GetFeedState("https://example.com/feed").NotifiedArticles[0].Skipped {
+		t.Error("Skipped should be true")
+	}
+}
+
+// TestUpdateNotifiedArticle は、通知済みの記事の更新の検出と記録をテストする
+func TestUpdateNotifiedArticle(t *testing.T) {
+	manager := NewManager("test.json")
+
+	publishedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Security Advisory",
+		URL:         "https://example.com/article-1",
+		Description: "Initial description",
+		PublishedAt: publishedAt,
+		UpdatedAt:   publishedAt,
+		FeedURL:     "https://example.com/feed",
+		MessageID:   "1001",
+	}
+	manager.MarkAsNotified(article)
+
+	notified := manager.GetNotifiedArticle("https://example.com/feed", "article-1")
+	if notified == nil {
+		t.Fatal("GetNotifiedArticle() = nil")
+	}
+	if notified.IsChanged(article) {
+		t.Error("IsChanged() = true for the notified content")
+	}
+
+	tests := []struct {
+		name   string
+		modify func(a *models.Article)
+		want   bool
+	}{
+		{
+			name:   "タイトルの変更",
+			modify: func(a *models.Article) { a.Title = "Security Advisory (revised)" },
+			want:   true,
+		},
+		{
+			name:   "説明文の変更",
+			modify: func(a *models.Article) { a.Description = "Corrected description" },
+			want:   true,
+		},
+		{
+			name:   "更新日時の変更",
+			modify: func(a *models.Article) { a.UpdatedAt = publishedAt.Add(time.Hour) },
+			want:   true,
+		},
+		{
+			name: "日時のない記事（取得時刻）",
+			modify: func(a *models.Article) {
+				a.PublishedAt = time.Now()
+				a.UpdatedAt = a.PublishedAt
+			},
+			want: false,
+		},
+		{
+			name:   "URLのみの変更",
+			modify: func(a *models.Article) { a.URL = "https://example.com/article-1?utm=1" },
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := *article
+			tt.modify(&changed)
+			if got := notified.IsChanged(&changed); got != tt.want {
+				t.Errorf("IsChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// 更新後の内容を記録すると、変更なしになる（メッセージIDは維持する）
+	updated := *article
+	updated.Title = "Security Advisory (revised)"
+	updated.MessageID = ""
+	manager.UpdateNotifiedArticle(&updated)
+
+	if notified.IsChanged(&updated) {
+		t.Error("IsChanged() = true after UpdateNotifiedArticle")
+	}
+	if notified.Title != "Security Advisory (revised)" || notified.MessageID != "1001" {
+		t.Errorf("Title = %q, MessageID = %q", notified.Title, notified.MessageID)
+	}
+
+	// ハッシュを記録していない記事は変更なしとして扱う
+	legacy := &models.NotifiedArticle{ID: "legacy", Title: "Old"}
+	if legacy.IsChanged(&updated) {
+		t.Error("IsChanged() = true for an article without content hash")
+	}
+}
+
+// TestMarkAsRetracted は、通知の取り下げの記録をテストする
+func TestMarkAsRetracted(t *testing.T) {
+	manager := NewManager("test.json")
+
+	manager.MarkAsNotified(&models.Article{
+		ID:          "article-1",
+		Title:       "Pulled Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedURL:     "https://example.com/feed",
+		MessageID:   "1001",
+	})
+
+	manager.MarkAsRetracted("https://example.com/feed", "article-1")
+	manager.MarkAsRetracted("https://example.com/feed", "unknown") // 存在しない記事は無視
+
+	notified := manager.GetNotifiedArticle("https://example.com/feed", "article-1")
+	if !notified.Retracted {
+		t.Error("Retracted should be true")
+	}
+
+	// 取り下げた記事も既読として扱う（再度通知しない）
+	if !manager.IsArticleNotified("https://example.com/feed", "article-1") {
+		t.Error("retracted article should be treated as notified")
+	}
+}
+
+// TestDeliveredRoutes は、通知先ごとの通知の記録をテストする
+func TestDeliveredRoutes(t *testing.T) {
+	manager := NewManager("test.json")
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Routed Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedURL:     "https://example.com/feed",
+	}
+
+	if manager.IsRouteDelivered(article.FeedURL, article.ID, "route-a") {
+		t.Error("route should not be delivered before it is recorded")
+	}
+
+	// 一部の通知先のみ通知に成功した記事は、通知済みとして扱わない
+	manager.MarkRouteDelivered(article, "route-a")
+	manager.MarkRouteDelivered(article, "route-a") // 重複は記録しない
+
+	if !manager.IsRouteDelivered(article.FeedURL, article.ID, "route-a") {
+		t.Error("route-a should be delivered")
+	}
+	if manager.IsRouteDelivered(article.FeedURL, article.ID, "route-b") {
+		t.Error("route-b should not be delivered")
+	}
+	if manager.IsArticleNotified(article.FeedURL, article.ID) {
+		t.Error("partially delivered article should not be treated as notified")
+	}
+	if got := len(manager.GetFeedState(article.FeedURL).DeliveredRoutes[article.ID]); got != 1 {
+		t.Errorf("delivered routes = %d, want 1", got)
+	}
+
+	// 通知済みとしてマークした時点で通知先の記録を削除する
+	manager.MarkAsNotified(article)
+
+	if manager.IsRouteDelivered(article.FeedURL, article.ID, "route-a") {
+		t.Error("delivered routes should be removed after the article is marked as notified")
+	}
+	if !manager.IsArticleNotified(article.FeedURL, article.ID) {
+		t.Error("article should be notified")
+	}
+}
+
+// TestIsMessageShared は、複数の記事を含むメッセージの判定をテストする
+func TestIsMessageShared(t *testing.T) {
+	manager := NewManager("test.json")
+
+	for i, messageID := range []string{"1001", "1002", "1002"} {
+		manager.MarkAsNotified(&models.Article{
+			ID:          fmt.Sprintf("article-%d", i),
+			Title:       "Test Article",
+			URL:         fmt.Sprintf("https://example.com/article-%d", i),
+			PublishedAt: time.Now(),
+			FeedURL:     fmt.Sprintf("https://example.com/feed-%d", i),
+			MessageID:   messageID,
+		})
+	}
+
+	tests := []struct {
+		messageID string
+		want      bool
+	}{
+		{"1001", false},
+		{"1002", true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := manager.IsMessageShared(tt.messageID); got != tt.want {
+			t.Errorf("IsMessageShared(%q) = %v, want %v", tt.messageID, got, tt.want)
+		}
+	}
 }
 
 // TestCleanup は、クリーンアップをテストする
+func TestDigestQueue(t *testing.T) {
+	manager := NewManager("test.json")
+
+	article := &models.Article{
+		ID:          "article-1",
+		Title:       "Digest Article",
+		URL:         "https://example.com/article-1",
+		PublishedAt: time.Now(),
+		FeedURL:     "https://example.com/feed",
+		FeedName:    "Test Feed",
+	}
+	manager.QueueForDigest(article)
+	manager.QueueForDigest(article) // 重複して追加しない
+
+	if len(manager.GetDigestQueue()) != 1 {
+		t.Fatalf("DigestQueue length = %d, want 1", len(manager.GetDigestQueue()))
+	}
+
+	// 待ちの記事は通知済みとして扱われ、フィードの状態も作成される
+	if !manager.IsArticleNotified("https://example.com/feed", "article-1") {
+		t.Error("queued article should be treated as notified")
+	}
+	if !manager.HasFeedState("https://example.com/feed") {
+		t.Error("feed state should be created when queued")
+	}
+
+	// 通知後は待ちから削除され、通知済みとして記録される
+	manager.MarkDigestAsNotified(manager.GetDigestQueue()[0].ToArticle())
+
+	if len(manager.GetDigestQueue()) != 0 {
+		t.Errorf("DigestQueue length = %d, want 0", len(manager.GetDigestQueue()))
+	}
+	if !manager.IsArticleNotified("https://example.com/feed", "article-1") {
+		t.Error("digest article should be notified")
+	}
+	if manager.state.Statistics.TotalArticlesNotified != 1 {
+		t.Errorf("TotalArticlesNotified = %d, want 1", manager.state.Statistics.TotalArticlesNotified)
+	}
+	if !manager.GetNotifiedArticle("https://example.com/feed", "article-1").Digest {
+		t.Error("Digest should be true")
+	}
+}
+
 func TestCleanup(t *testing.T) {
 	manager := NewManager("test.json")
 	manager.SetCleanupDays(7)
@@ -217,6 +497,34 @@ func TestIsFirstRun(t *testing.T) {
 	}
 }
 
+// TestHasFeedState は、フィードの状態の存在チェックをテストする
+func TestHasFeedState(t *testing.T) {
+	manager := NewManager("test.json")
+	feedURL := "https://example.com/feed"
+
+	// 初期状態では存在しない
+	if manager.HasFeedState(feedURL) {
+		t.Error("feed state should not exist initially")
+	}
+
+	// 既読チェックでは作成されない
+	manager.IsArticleNotified(feedURL, "article-1")
+	if manager.HasFeedState(feedURL) {
+		t.Error("IsArticleNotified() should not create feed state")
+	}
+
+	// 記事を記録すると存在する
+	manager.MarkAsSkipped(&models.Article{
+		ID:      "article-1",
+		Title:   "Test Article",
+		URL:     "https://example.com/article-1",
+		FeedURL: feedURL,
+	})
+	if !manager.HasFeedState(feedURL) {
+		t.Error("feed state should exist after recording an article")
+	}
+}
+
 // TestUpdateStatistics は、統計情報の更新をテストする
 func TestUpdateStatistics(t *testing.T) {
 	manager := NewManager("test.json")
@@ -274,3 +582,36 @@ func TestReset(t *testing.T) {
 		t.Errorf("should be first run after reset (feeds count: %d)", feedsCount)
 	}
 }
+
+// TestCacheValidators は、条件付きGET用の検証子の保存と取得をテストする
+func TestCacheValidators(t *testing.T) {
+	manager := NewManager("test.json")
+	feedURL := "https://example.com/feed"
+
+	// 初期状態では空
+	if len(manager.GetCacheValidators()) != 0 {
+		t.Error("GetCacheValidators() should be empty initially")
+	}
+
+	manager.SetCacheValidators(feedURL, models.CacheValidators{
+		ETag:         `"abc"`,
+		LastModified: "Wed, 12 Nov 2025 10:00:00 GMT",
+	})
+
+	got, ok := manager.GetCacheValidators()[feedURL]
+	if !ok {
+		t.Fatal("validators should exist after SetCacheValidators()")
+	}
+	if got.ETag != `"abc"` {
+		t.Errorf("ETag = %v, want %v", got.ETag, `"abc"`)
+	}
+	if got.LastModified != "Wed, 12 Nov 2025 10:00:00 GMT" {
+		t.Errorf("LastModified = %v, want %v", got.LastModified, "Wed, 12 Nov 2025 10:00:00 GMT")
+	}
+
+	// 空の検証子は返さない
+	manager.SetCacheValidators(feedURL, models.CacheValidators{})
+	if _, ok := manager.GetCacheValidators()[feedURL]; ok {
+		t.Error("empty validators should not be returned")
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
 
 // Article は、RSSフィードから取得した記事を表すモデル
 type Article struct {
@@ -16,6 +20,9 @@ type Article struct {
 	// Description は記事の説明文または要約
 	Description string
 
+	// DescriptionIsMarkdown は Description がDiscordのMarkdownに変換済みかどうか
+	DescriptionIsMarkdown bool
+
 	// Content は記事の本文（RSSフィードによっては空の場合がある）
 	Content string
 
@@ -37,12 +44,41 @@ type Article struct {
 	// Category はフィードのカテゴリ（Tech, News, Blog, Otherなど）
 	Category string
 
+	// Tags は記事に付与されたカテゴリ（RSSの <category> など）
+	Tags []string
+
 	// WebhookURL はこの記事を通知する際に使用するDiscord Webhook URL
 	// フィード設定から引き継がれる
 	WebhookURL string
 
 	// ImageURL は記事のサムネイル画像URL（存在する場合）
 	ImageURL string
+
+	// Mentions は通知メッセージに付与するメンション
+	Mentions []string
+
+	// Routes はルーティングルールで決定した通知先
+	// 空の場合は WebhookURL（またはデフォルトのWebhook URL）に通知する
+	Routes []*Route
+
+	// MessageID は通知したDiscordのメッセージID（通知後に設定される）
+	MessageID string
+
+	// ChannelID は通知したDiscordのチャンネルID（通知後に設定される）
+	ChannelID string
+
+	// WebhookID は通知に使用したDiscordのWebhookのID（通知後に設定される）
+	WebhookID string
+}
+
+// Route は、ルーティングルールで決定した記事の通知先を表すモデル
+type Route struct {
+	// WebhookURL は通知先のDiscord Webhook URL
+	// 空の場合は記事の WebhookURL（またはデフォルトのWebhook URL）に通知する
+	WebhookURL string
+
+	// Mentions は通知メッセージに付与するメンション
+	Mentions []string
 }
 
 // IsValid は、記事が有効なデータを持っているかチェックする
@@ -51,16 +87,24 @@ func (a *Article) IsValid() bool {
 	return a.ID != "" && a.Title != "" && a.URL != ""
 }
 
-// GetShortDescription は、説明文を指定された長さに切り詰める
+// ContentHash は、更新の検出に使用する記事の内容（タイトルと説明文）のハッシュを返す
+func (a *Article) ContentHash() string {
+	sum := sha256.Sum256([]byte(a.Title + "\n" + a.Description))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GetShortDescription は、説明文を指定された文字数（rune単位）に切り詰める
+// マルチバイト文字（日本語など）の途中で切らないよう、バイト数ではなく文字数で数える
 func (a *Article) GetShortDescription(maxLength int) string {
-	if len(a.Description) <= maxLength {
+	runes := []rune(a.Description)
+	if len(runes) <= maxLength {
 		return a.Description
 	}
 
 	// maxLengthを超える場合は切り詰めて "..." を追加
 	if maxLength > 3 {
-		return a.Description[:maxLength-3] + "..."
+		return string(runes[:maxLength-3]) + "..."
 	}
 
-	return a.Description[:maxLength]
+	return string(runes[:max(maxLength, 0)])
 }
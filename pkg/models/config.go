@@ -1,5 +1,19 @@
 package models
 
+import "fmt"
+
+// 通知する記事数の上限の配分方式
+const (
+	// QuotaModeNewest はフィードを問わず新しい順に選ぶ（デフォルト）
+	QuotaModeNewest = "newest"
+
+	// QuotaModeFeed はフィードごとに優先度に応じて均等に選ぶ
+	QuotaModeFeed = "feed"
+
+	// QuotaModeCategory はカテゴリごとに優先度に応じて均等に選ぶ
+	QuotaModeCategory = "category"
+)
+
 // Config は、アプリケーション全体の設定を表すモデル
 type Config struct {
 	// Version は設定ファイルのバージョン
@@ -8,8 +22,42 @@ type Config struct {
 	// Notification は通知に関する設定
 	Notification *NotificationConfig `yaml:"notification"`
 
+	// HTTP はフィード取得とDiscord通知で共有するHTTP通信の設定（オプション）
+	HTTP *HTTPConfig `yaml:"http,omitempty"`
+
 	// Feeds は監視するRSSフィードのリスト
 	Feeds []*FeedConfig `yaml:"feeds"`
+
+	// Rules は記事の内容に応じて通知先を決定するルーティングルール（オプション）
+	Rules []*RoutingRule `yaml:"rules,omitempty"`
+
+	// Mentions はすべてのフィードの記事に付与するメンション（オプション）
+	// match でキーワードに一致した記事のみをメンションする場合に使用する
+	Mentions []*MentionConfig `yaml:"mentions,omitempty"`
+
+	// Categories はカテゴリごとの設定（オプション）
+	// キーはフィードの category に指定した値
+	Categories map[string]*CategoryConfig `yaml:"categories,omitempty"`
+
+	// Templates は名前付きの通知メッセージのテンプレート（オプション）
+	// フィードまたはカテゴリの template に名前を指定して使用する
+	Templates map[string]*MessageTemplate `yaml:"templates,omitempty"`
+}
+
+// CategoryConfig は、カテゴリに属するフィードに共通の設定を表すモデル
+// フィードに同じ項目の指定がある場合はフィードの設定が優先される
+type CategoryConfig struct {
+	// Delivery はカテゴリに属するフィードの記事の通知方法（immediate, digest）
+	Delivery string `yaml:"delivery,omitempty"`
+
+	// DigestSchedule は delivery: digest の場合にダイジェストを通知する日時（例: "daily 09:00", "weekly mon 09:00"）
+	DigestSchedule string `yaml:"digest_schedule,omitempty"`
+
+	// Template はカテゴリに属するフィードの記事に使用するテンプレートの名前（templates のキー）
+	Template string `yaml:"template,omitempty"`
+
+	// Mentions はカテゴリに属するフィードの記事に付与するメンション
+	Mentions []*MentionConfig `yaml:"mentions,omitempty"`
 }
 
 // NotificationConfig は、通知に関する設定を表すモデル
@@ -21,9 +69,83 @@ type NotificationConfig struct {
 	TimeoutSeconds int `yaml:"timeout_seconds"`
 
 	// RateLimitMs はDiscord通知間隔（ミリ秒）
+	// Discordのレート制限ヘッダーの情報がないWebhookへの送信にのみ使用する
 	RateLimitMs int `yaml:"rate_limit_ms"`
+
+	// MaxRateLimitWaitSeconds はDiscordのレート制限で待機する時間の上限（秒）
+	// 待機時間がこれを超える場合は送信を諦め、次回の実行で再通知する
+	MaxRateLimitWaitSeconds int `yaml:"max_rate_limit_wait_seconds"`
+
+	// MaxConcurrency はフィードの同時取得数の上限
+	MaxConcurrency int `yaml:"max_concurrency"`
+
+	// MaxConcurrencyPerHost は同一ホストへのフィードの同時取得数の上限
+	MaxConcurrencyPerHost int `yaml:"max_concurrency_per_host"`
+
+	// FetchMaxRetries はフィード取得に失敗した場合の最大リトライ回数（0でリトライしない）
+	FetchMaxRetries *int `yaml:"fetch_max_retries"`
+
+	// FetchRetryMaxDelaySeconds はフィード取得のリトライ間隔の上限（秒）
+	// Retry-After がこれを超える場合はリトライしない
+	FetchRetryMaxDelaySeconds int `yaml:"fetch_retry_max_delay_seconds"`
+
+	// MaxAge は通知する記事の公開日時からの最大経過時間（例: 72h, 7d、オプション）
+	// これより古い記事は通知せずに既読として記録する（公開日時が未来の記事は指定の有無にかかわらず公開日時まで保留する）
+	MaxAge string `yaml:"max_age,omitempty"`
+
+	// Since はこれより前に公開された記事を通知しない日時（例: 2025-01-01、オプション）
+	Since string `yaml:"since,omitempty"`
+
+	// EmbedsPerMessage は同じWebhookに通知する記事を1つのメッセージにまとめる件数（1〜10）
+	// 1の場合は記事ごとにメッセージを送信する。Embedの文字数の合計が6000文字を超える場合は分割する
+	EmbedsPerMessage int `yaml:"embeds_per_message"`
+
+	// QuotaMode は max_articles_per_run を超えた場合の配分方式（newest, feed, category）
+	// feed / category の場合は、フィードまたはカテゴリごとに priority の件数ずつ順番に選ぶ
+	QuotaMode string `yaml:"quota_mode,omitempty"`
+
+	// FirstRunArticles は初めて取得したフィード（状態を持たないフィード）で通知する最新記事数
+	// それ以外の記事は通知せずに既読として記録する。0 の場合は1件も通知しない
+	FirstRunArticles *int `yaml:"first_run_articles"`
+
+	// Timezone はダイジェストの通知日時の解釈に使用するタイムゾーン（例: Asia/Tokyo、オプション）
+	// 指定がない場合は実行環境のタイムゾーン（TZ 環境変数）
+	Timezone string `yaml:"timezone,omitempty"`
 }
 
+// HTTPConfig は、HTTP通信（プロキシ・TLS）に関する設定を表すモデル
+type HTTPConfig struct {
+	// ProxyURL はHTTPプロキシのURL（例: http://proxy.example.com:8080）
+	// 指定がない場合は環境変数 HTTP_PROXY / HTTPS_PROXY / NO_PROXY に従う
+	ProxyURL string `yaml:"proxy_url,omitempty"`
+
+	// CACertFile はシステムのCA証明書に追加するCA証明書（PEM形式）のパス
+	CACertFile string `yaml:"ca_cert_file,omitempty"`
+
+	// ClientCertFile はクライアント証明書（PEM形式）のパス
+	ClientCertFile string `yaml:"client_cert_file,omitempty"`
+
+	// ClientKeyFile はクライアント証明書の秘密鍵（PEM形式）のパス
+	ClientKeyFile string `yaml:"client_key_file,omitempty"`
+
+	// MaxIdleConnsPerHost はホストごとに保持するアイドル接続数の上限
+	MaxIdleConnsPerHost int `yaml:"max_idle_conns_per_host,omitempty"`
+}
+
+const (
+	// デフォルトのフィード取得リトライ回数
+	defaultFetchMaxRetries = 2
+
+	// 初めて取得したフィードで通知するデフォルトの記事数
+	defaultFirstRunArticles = 5
+
+	// Discordのレート制限で待機する時間のデフォルトの上限（秒）
+	defaultMaxRateLimitWaitSeconds = 60
+
+	// 1つのメッセージにまとめられる記事数の上限（DiscordのEmbed数の制限）
+	maxEmbedsPerMessage = 10
+)
+
 // GetEnabledFeeds は、有効なフィードのみを返す
 func (c *Config) GetEnabledFeeds() []*FeedConfig {
 	enabledFeeds := make([]*FeedConfig, 0)
@@ -42,9 +164,13 @@ func (c *Config) Validate() error {
 	if c.Notification == nil {
 		// デフォルト値を設定
 		c.Notification = &NotificationConfig{
-			MaxArticlesPerRun: 10,
-			TimeoutSeconds:    30,
-			RateLimitMs:       1000,
+			MaxArticlesPerRun:       10,
+			TimeoutSeconds:          30,
+			RateLimitMs:             1000,
+			MaxRateLimitWaitSeconds: defaultMaxRateLimitWaitSeconds,
+			MaxConcurrency:          10,
+			MaxConcurrencyPerHost:   2,
+			EmbedsPerMessage:        1,
 		}
 	}
 
@@ -61,5 +187,51 @@ func (c *Config) Validate() error {
 		c.Notification.RateLimitMs = 1000
 	}
 
+	if c.Notification.MaxRateLimitWaitSeconds <= 0 {
+		c.Notification.MaxRateLimitWaitSeconds = defaultMaxRateLimitWaitSeconds
+	}
+
+	if c.Notification.EmbedsPerMessage <= 0 {
+		c.Notification.EmbedsPerMessage = 1
+	}
+
+	if c.Notification.EmbedsPerMessage > maxEmbedsPerMessage {
+		c.Notification.EmbedsPerMessage = maxEmbedsPerMessage
+	}
+
+	if c.Notification.MaxConcurrency <= 0 {
+		c.Notification.MaxConcurrency = 10
+	}
+
+	if c.Notification.MaxConcurrencyPerHost <= 0 {
+		c.Notification.MaxConcurrencyPerHost = 2
+	}
+
+	if c.Notification.FetchMaxRetries == nil || *c.Notification.FetchMaxRetries < 0 {
+		retries := defaultFetchMaxRetries
+		c.Notification.FetchMaxRetries = &retries
+	}
+
+	if c.Notification.FetchRetryMaxDelaySeconds <= 0 {
+		c.Notification.FetchRetryMaxDelaySeconds = 30
+	}
+
+	if c.Notification.FirstRunArticles == nil || *c.Notification.FirstRunArticles < 0 {
+		articles := defaultFirstRunArticles
+		c.Notification.FirstRunArticles = &articles
+	}
+
+	switch c.Notification.QuotaMode {
+	case "":
+		c.Notification.QuotaMode = QuotaModeNewest
+	case QuotaModeNewest, QuotaModeFeed, QuotaModeCategory:
+	default:
+		return fmt.Errorf("invalid quota_mode: %s", c.Notification.QuotaMode)
+	}
+
+	if c.HTTP != nil && c.HTTP.MaxIdleConnsPerHost <= 0 {
+		c.HTTP.MaxIdleConnsPerHost = 10
+	}
+
 	return nil
 }
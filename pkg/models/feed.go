@@ -1,14 +1,95 @@
 package models
 
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// フィードの種類
+const (
+	// FeedTypeRSS はRSS / Atom / JSON Feed のフィード（デフォルト）
+	FeedTypeRSS = "rss"
+
+	// FeedTypeHTML はフィードを提供していないWebページ（CSSセレクターで記事を抽出）
+	FeedTypeHTML = "html"
+)
+
+// キーワードフィルターの対象フィールド
+const (
+	// FilterFieldTitle は記事のタイトル
+	FilterFieldTitle = "title"
+
+	// FilterFieldDescription は記事の説明文
+	FilterFieldDescription = "description"
+
+	// FilterFieldAuthor は記事の著者名
+	FilterFieldAuthor = "author"
+
+	// FilterFieldCategory はフィードのカテゴリと記事に付与されたカテゴリ（タグ）
+	FilterFieldCategory = "category"
+)
+
+// 記事の説明文の形式
+const (
+	// DescriptionFormatText はHTMLをプレーンテキストに変換する（デフォルト）
+	DescriptionFormatText = "text"
+
+	// DescriptionFormatMarkdown はHTMLをDiscordのMarkdownに変換する
+	DescriptionFormatMarkdown = "markdown"
+)
+
+// 記事の通知方法
+const (
+	// DeliveryImmediate は記事ごとにすぐ通知する（デフォルト）
+	DeliveryImmediate = "immediate"
+
+	// DeliveryDigest は記事を溜めておき、指定した日時にまとめて通知する
+	DeliveryDigest = "digest"
+)
+
+// 通知済みの記事が更新された場合の動作
+const (
+	// UpdateActionIgnore は更新を通知しない（デフォルト）
+	UpdateActionIgnore = "ignore"
+
+	// UpdateActionEdit は通知済みのメッセージを更新後の内容に編集する
+	UpdateActionEdit = "edit"
+
+	// UpdateActionReply は更新を知らせるメッセージを追加で通知する
+	UpdateActionReply = "reply"
+)
+
+// 通知済みの記事がフィードから削除された場合の動作
+const (
+	// RemoveActionIgnore は何もしない（デフォルト）
+	RemoveActionIgnore = "ignore"
+
+	// RemoveActionDelete は通知済みのメッセージを削除する
+	RemoveActionDelete = "delete"
+
+	// RemoveActionEdit は通知済みのメッセージを取り下げの表示に編集する
+	RemoveActionEdit = "edit"
+
+	// defaultOnRemoveWithinHours は削除を確認する通知後の時間のデフォルト
+	defaultOnRemoveWithinHours = 24
+)
+
 // FeedConfig は、RSSフィードの設定を表すモデル
 // feeds.yaml から読み込まれる
 type FeedConfig struct {
 	// Name はフィードの表示名
 	Name string `yaml:"name"`
 
-	// URL はRSSフィードのURL
+	// URL はRSSフィードのURL（type: html の場合は記事一覧ページのURL）
 	URL string `yaml:"url"`
 
+	// Type はフィードの種類（rss, html）。指定がない場合は rss
+	Type string `yaml:"type,omitempty"`
+
+	// Selectors は type: html の場合に記事を抽出するCSSセレクター
+	Selectors *HTMLSelectors `yaml:"selectors,omitempty"`
+
 	// Category はフィードのカテゴリ（Tech, News, Blog, Otherなど）
 	Category string `yaml:"category"`
 
@@ -19,10 +100,258 @@ type FeedConfig struct {
 	// 環境変数を参照する場合は ${ENV_VAR_NAME} の形式で指定
 	// 指定がない場合はデフォルトのWebhook URLが使用される
 	WebhookURL string `yaml:"webhook_url,omitempty"`
+
+	// MaxRetries はこのフィードの取得に失敗した場合の最大リトライ回数（オプション）
+	// 指定がない場合は notification.fetch_max_retries が使用される
+	MaxRetries *int `yaml:"max_retries,omitempty"`
+
+	// RetryMaxDelaySeconds はこのフィードのリトライ間隔の上限（秒、オプション）
+	// 指定がない場合は notification.fetch_retry_max_delay_seconds が使用される
+	RetryMaxDelaySeconds int `yaml:"retry_max_delay_seconds,omitempty"`
+
+	// UserAgent はこのフィードの取得時に使用するUser-Agent（オプション）
+	// 指定がない場合はデフォルトのUser-Agentが使用される
+	UserAgent string `yaml:"user_agent,omitempty"`
+
+	// Headers はこのフィードの取得時に付与するHTTPヘッダー（オプション）
+	// 値には ${ENV_VAR_NAME} の形式で環境変数を参照できる
+	Headers map[string]string `yaml:"headers,omitempty"`
+
+	// Auth はこのフィードの取得時に使用する認証情報（オプション）
+	Auth *FeedAuth `yaml:"auth,omitempty"`
+
+	// DescriptionFormat は記事の説明文の形式（text, markdown）。指定がない場合は text
+	// markdown の場合はリンク・強調・コード・リストをDiscordのMarkdownに変換する
+	DescriptionFormat string `yaml:"description_format,omitempty"`
+
+	// FetchOGImage はフィードに画像が含まれない記事について、記事ページの og:image / twitter:image を取得するかどうか
+	// 通知する記事ごとに記事ページへのリクエストが発生する
+	FetchOGImage bool `yaml:"fetch_og_image,omitempty"`
+
+	// MaxAge はこのフィードで通知する記事の公開日時からの最大経過時間（例: 72h, 7d、オプション）
+	// 指定がない場合は notification.max_age が使用される。"0" で制限なし
+	MaxAge string `yaml:"max_age,omitempty"`
+
+	// Since はこのフィードでこれより前に公開された記事を通知しない日時（例: 2025-01-01、オプション）
+	// 指定がない場合は notification.since が使用される。"0" で制限なし
+	Since string `yaml:"since,omitempty"`
+
+	// FirstRunArticles は初めて取得した際に通知する最新記事数（オプション）
+	// 指定がない場合は notification.first_run_articles が使用される。0 の場合は1件も通知しない
+	FirstRunArticles *int `yaml:"first_run_articles,omitempty"`
+
+	// Priority は通知する記事数の上限を配分する際の重み（オプション、デフォルト: 1）
+	// notification.quota_mode が feed / category の場合に、1巡ごとにこの件数ずつ選ばれる
+	Priority int `yaml:"priority,omitempty"`
+
+	// Delivery は記事の通知方法（immediate, digest、オプション）
+	// 指定がない場合はカテゴリの設定（categories）、それもない場合は immediate
+	Delivery string `yaml:"delivery,omitempty"`
+
+	// DigestSchedule は delivery: digest の場合にダイジェストを通知する日時（例: "daily 09:00", "weekly mon 09:00"）
+	// 指定がない場合はカテゴリの設定が使用される
+	DigestSchedule string `yaml:"digest_schedule,omitempty"`
+
+	// OnUpdate は通知済みの記事のタイトル・説明文・更新日時が変わった場合の動作（ignore, edit, reply、オプション）
+	// edit は通知済みのメッセージを編集し、編集できない場合（複数の記事をまとめたメッセージなど）は reply と同じく追加で通知する
+	OnUpdate string `yaml:"on_update,omitempty"`
+
+	// OnRemove は通知済みの記事がフィードから削除された場合の動作（ignore, delete, edit、オプション）
+	// 公開の取り下げなどに対応するため、通知から on_remove_within_hours 以内の記事のみを対象とする
+	OnRemove string `yaml:"on_remove,omitempty"`
+
+	// OnRemoveWithinHours は on_remove の対象とする通知後の時間（オプション、デフォルト: 24）
+	OnRemoveWithinHours int `yaml:"on_remove_within_hours,omitempty"`
+
+	// Mentions は記事の通知メッセージに付与するメンション（オプション）
+	// 指定した場合はカテゴリの mentions の代わりに使用する（空のリスト [] でカテゴリのメンションを無効化）
+	Mentions []*MentionConfig `yaml:"mentions,omitempty"`
+
+	// Template は通知メッセージに使用するテンプレートの名前（templates のキー、オプション）
+	// 指定がない場合はカテゴリの設定、それもない場合はデフォルトの表示
+	Template string `yaml:"template,omitempty"`
+
+	// Include は通知対象とする記事の条件（オプション）
+	// 指定した場合、いずれかのルールに一致する記事のみを通知する
+	Include []*FilterRule `yaml:"include,omitempty"`
+
+	// Exclude は通知対象から除外する記事の条件（オプション）
+	// いずれかのルールに一致する記事は Include に一致していても通知しない
+	Exclude []*FilterRule `yaml:"exclude,omitempty"`
+
+	// InsecureSkipVerify はこのフィードの取得時にTLS証明書の検証を無効化するかどうか
+	// 自己署名証明書の社内サーバーなど、やむを得ない場合のみ使用する
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify,omitempty"`
+}
+
+// HTMLSelectors は、Webページから記事を抽出するためのCSSセレクターを表すモデル
+// item 以外のセレクターは各記事要素からの相対指定
+type HTMLSelectors struct {
+	// Item は記事1件を表す要素のセレクター（必須）
+	Item string `yaml:"item"`
+
+	// Title は記事タイトルのセレクター（省略時は記事要素のテキスト）
+	Title string `yaml:"title,omitempty"`
+
+	// Link は記事URLを持つ要素のセレクター（省略時は最初の a[href]）
+	Link string `yaml:"link,omitempty"`
+
+	// Date は公開日時のセレクター（datetime 属性またはテキストを使用）
+	Date string `yaml:"date,omitempty"`
+
+	// DateFormat は公開日時のフォーマット（Goのレイアウト形式、省略時は一般的な形式を順に試す）
+	DateFormat string `yaml:"date_format,omitempty"`
+
+	// Summary は記事の要約のセレクター
+	Summary string `yaml:"summary,omitempty"`
+}
+
+// FeedAuth は、非公開フィードの取得に使用する認証情報を表すモデル
+// 各値には ${ENV_VAR_NAME} の形式で環境変数を参照できる
+type FeedAuth struct {
+	// BearerToken は Authorization: Bearer ヘッダーに使用するトークン
+	BearerToken string `yaml:"bearer_token,omitempty"`
+
+	// Username はBasic認証のユーザー名
+	Username string `yaml:"username,omitempty"`
+
+	// Password はBasic認証のパスワード
+	Password string `yaml:"password,omitempty"`
+
+	// Cookie は Cookie ヘッダーに使用する値（例: "session=xxxx"）
+	Cookie string `yaml:"cookie,omitempty"`
+}
+
+// Validate は、認証設定が有効かチェックする
+func (a *FeedAuth) Validate() error {
+	if a.BearerToken != "" && (a.Username != "" || a.Password != "") {
+		return fmt.Errorf("bearer_token and username/password cannot be used together")
+	}
+	if a.Password != "" && a.Username == "" {
+		return fmt.Errorf("username is required for basic auth")
+	}
+	return nil
+}
+
+// FilterRule は、記事を絞り込むキーワードフィルターのルールを表すモデル
+// Keywords と Patterns のいずれかが Fields のいずれかに一致した場合にルールに一致する
+type FilterRule struct {
+	// Keywords は大文字小文字を区別せずに部分一致で判定するキーワード
+	Keywords []string `yaml:"keywords,omitempty"`
+
+	// Patterns は正規表現（Goの regexp 構文）
+	Patterns []string `yaml:"patterns,omitempty"`
+
+	// Fields は判定対象のフィールド（title, description, author, category）
+	// 指定がない場合は title と description
+	Fields []string `yaml:"fields,omitempty"`
+}
+
+// Validate は、フィルタールールが有効かチェックする
+func (r *FilterRule) Validate() error {
+	if len(r.Keywords) == 0 && len(r.Patterns) == 0 {
+		return fmt.Errorf("keywords or patterns is required")
+	}
+
+	for _, field := range r.Fields {
+		switch field {
+		case FilterFieldTitle, FilterFieldDescription, FilterFieldAuthor, FilterFieldCategory:
+		default:
+			return fmt.Errorf("unknown field: %s", field)
+		}
+	}
+
+	for _, pattern := range r.Patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
 }
 
 // IsValid は、フィード設定が有効かチェックする
 func (f *FeedConfig) IsValid() bool {
 	// 最低限、名前とURLが必要
-	return f.Name != "" && f.URL != ""
+	if f.Name == "" || f.URL == "" {
+		return false
+	}
+
+	switch f.DescriptionFormat {
+	case "", DescriptionFormatText, DescriptionFormatMarkdown:
+	default:
+		return false
+	}
+
+	switch f.OnUpdate {
+	case "", UpdateActionIgnore, UpdateActionEdit, UpdateActionReply:
+	default:
+		return false
+	}
+
+	switch f.OnRemove {
+	case "", RemoveActionIgnore, RemoveActionDelete, RemoveActionEdit:
+	default:
+		return false
+	}
+	if f.OnRemoveWithinHours < 0 {
+		return false
+	}
+
+	switch f.Type {
+	case "", FeedTypeRSS:
+		return true
+	case FeedTypeHTML:
+		// HTMLの場合は記事要素のセレクターが必要
+		return f.Selectors != nil && f.Selectors.Item != ""
+	default:
+		return false
+	}
+}
+
+// GetFirstRunArticles は、初めて取得した際に通知する記事数を返す
+// フィードに指定がない場合は defaultValue を返す
+func (f *FeedConfig) GetFirstRunArticles(defaultValue int) int {
+	if f.FirstRunArticles != nil && *f.FirstRunArticles >= 0 {
+		return *f.FirstRunArticles
+	}
+	return defaultValue
+}
+
+// GetPriority は、通知する記事数の上限を配分する際の重みを返す（1以上）
+func (f *FeedConfig) GetPriority() int {
+	if f.Priority > 0 {
+		return f.Priority
+	}
+	return 1
+}
+
+// GetOnUpdate は、通知済みの記事が更新された場合の動作を返す（指定がない場合は ignore）
+func (f *FeedConfig) GetOnUpdate() string {
+	if f.OnUpdate == "" {
+		return UpdateActionIgnore
+	}
+	return f.OnUpdate
+}
+
+// GetOnRemove は、通知済みの記事がフィードから削除された場合の動作を返す（指定がない場合は ignore）
+func (f *FeedConfig) GetOnRemove() string {
+	if f.OnRemove == "" {
+		return RemoveActionIgnore
+	}
+	return f.OnRemove
+}
+
+// GetOnRemoveWithin は、on_remove の対象とする通知後の時間を返す
+func (f *FeedConfig) GetOnRemoveWithin() time.Duration {
+	hours := f.OnRemoveWithinHours
+	if hours <= 0 {
+		hours = defaultOnRemoveWithinHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// IsHTML は、フィードを提供していないWebページを対象とする設定かどうかを返す
+func (f *FeedConfig) IsHTML() bool {
+	return f.Type == FeedTypeHTML
 }
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// snowflakePattern は、DiscordのID（ロールID・ユーザーID）の形式
+var snowflakePattern = regexp.MustCompile(`^\d{1,20}$`)
+
+// MentionConfig は、通知メッセージに付与するメンションの設定を表すモデル
+// フィード・カテゴリ、またはトップレベルの mentions（すべてのフィード）に指定する
+type MentionConfig struct {
+	// Roles はメンションするロールのID
+	Roles []string `yaml:"roles,omitempty"`
+
+	// Users はメンションするユーザーのID
+	Users []string `yaml:"users,omitempty"`
+
+	// Here は @here でメンションするかどうか
+	Here bool `yaml:"here,omitempty"`
+
+	// Match はメンションする記事の条件（オプション）
+	// 指定した場合、いずれかのルールに一致する記事のみをメンションする
+	Match []*FilterRule `yaml:"match,omitempty"`
+}
+
+// Validate は、メンションの設定が有効かチェックする
+func (m *MentionConfig) Validate() error {
+	if len(m.Roles) == 0 && len(m.Users) == 0 && !m.Here {
+		return fmt.Errorf("one of roles, users or here is required")
+	}
+
+	for _, id := range m.Roles {
+		if !snowflakePattern.MatchString(id) {
+			return fmt.Errorf("invalid role ID: %q", id)
+		}
+	}
+	for _, id := range m.Users {
+		if !snowflakePattern.MatchString(id) {
+			return fmt.Errorf("invalid user ID: %q", id)
+		}
+	}
+
+	for _, rule := range m.Match {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Mentions は、メッセージ本文に記載するメンション（@here, <@&ROLE_ID>, <@USER_ID>）を返す
+func (m *MentionConfig) Mentions() []string {
+	mentions := make([]string, 0, len(m.Roles)+len(m.Users)+1)
+
+	if m.Here {
+		mentions = append(mentions, "@here")
+	}
+	for _, id := range m.Roles {
+		mentions = append(mentions, "<@&"+id+">")
+	}
+	for _, id := range m.Users {
+		mentions = append(mentions, "<@"+id+">")
+	}
+
+	return mentions
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ruleMentionPattern は、ルーティングルールに指定できるメンション（@here, @everyone, <@&ROLE_ID>, <@USER_ID>, <@!USER_ID>）の形式
+var ruleMentionPattern = regexp.MustCompile(`^(@here|@everyone|<@&\d{1,20}>|<@!?\d{1,20}>)$`)
+
+// RoutingRule は、記事の内容に応じて通知先を決定するルーティングルールを表すモデル
+// feeds.yaml のトップレベルの rules から読み込まれ、すべてのフィードの記事に上から順に適用される
+type RoutingRule struct {
+	// Name はルールの名前（ログ出力用、オプション）
+	Name string `yaml:"name,omitempty"`
+
+	// When は記事に対する条件式（例: category == "Tech" && title matches "(?i)kubernetes"）
+	// 指定がない場合はすべての記事に一致する
+	When string `yaml:"when,omitempty"`
+
+	// WebhookURL は条件に一致した記事の通知先のDiscord Webhook URL
+	// 環境変数を参照する場合は ${ENV_VAR_NAME} の形式で指定
+	// 一致したルールが複数ある場合は、それぞれの通知先に通知する
+	WebhookURL string `yaml:"webhook_url,omitempty"`
+
+	// Mention は通知メッセージに付与するメンション（@here, @everyone, <@&ROLE_ID>, <@USER_ID> のいずれか）
+	// WebhookURL を指定しない場合は、すべての通知先のメッセージに付与する
+	Mention string `yaml:"mention,omitempty"`
+
+	// Drop は条件に一致した記事を通知せずに破棄するかどうか
+	Drop bool `yaml:"drop,omitempty"`
+
+	// Stop は条件に一致した場合に以降のルールを評価しないかどうか
+	Stop bool `yaml:"stop,omitempty"`
+}
+
+// Validate は、ルーティングルールが有効かチェックする（条件式の構文は含まない）
+func (r *RoutingRule) Validate() error {
+	if r.Drop {
+		if r.WebhookURL != "" || r.Mention != "" {
+			return fmt.Errorf("drop cannot be used with webhook_url or mention")
+		}
+		return nil
+	}
+
+	if r.WebhookURL == "" && r.Mention == "" && !r.Stop {
+		return fmt.Errorf("one of webhook_url, mention, drop or stop is required")
+	}
+
+	if r.Mention != "" && !ruleMentionPattern.MatchString(r.Mention) {
+		return fmt.Errorf("invalid mention: %q (use @here, @everyone, <@&ROLE_ID> or <@USER_ID>)", r.Mention)
+	}
+
+	return nil
+}
+
+// DisplayName は、ログ出力用のルール名を返す
+func (r *RoutingRule) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.When
+}
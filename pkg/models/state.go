@@ -16,6 +16,9 @@ type State struct {
 
 	// Statistics は統計情報
 	Statistics *Statistics `json:"statistics"`
+
+	// DigestQueue はダイジェストでの通知を待っている記事
+	DigestQueue []*PendingDigestArticle `json:"digest_queue,omitempty"`
 }
 
 // FeedState は、個別のフィードの状態を表すモデル
@@ -25,6 +28,30 @@ type FeedState struct {
 
 	// NotifiedArticles は通知済みの記事のリスト
 	NotifiedArticles []*NotifiedArticle `json:"notified_articles"`
+
+	// ETag は前回取得時にサーバーから返されたETag（条件付きGET用）
+	ETag string `json:"etag,omitempty"`
+
+	// LastModified は前回取得時にサーバーから返されたLast-Modified（条件付きGET用）
+	LastModified string `json:"last_modified,omitempty"`
+
+	// DeliveredRoutes は、一部の通知先への通知に失敗した記事について、通知に成功した通知先のキー（記事IDごと）
+	// すべての通知先に通知して通知済みとして記録した時点で削除する
+	DeliveredRoutes map[string][]string `json:"delivered_routes,omitempty"`
+}
+
+// CacheValidators は、条件付きGETに使用する検証子を表すモデル
+type CacheValidators struct {
+	// ETag は If-None-Match ヘッダーに使用する値
+	ETag string
+
+	// LastModified は If-Modified-Since ヘッダーに使用する値
+	LastModified string
+}
+
+// IsEmpty は、検証子が1つも設定されていないかチェックする
+func (v CacheValidators) IsEmpty() bool {
+	return v.ETag == "" && v.LastModified == ""
 }
 
 // NotifiedArticle は、通知済みの記事を表すモデル
@@ -41,8 +68,122 @@ type NotifiedArticle struct {
 	// PublishedAt は記事の公開日時
 	PublishedAt time.Time `json:"published_at"`
 
-	// NotifiedAt は通知を送信した日時
+	// NotifiedAt は通知を送信した日時（Skipped の場合は記録した日時）
 	NotifiedAt time.Time `json:"notified_at"`
+
+	// Skipped はフィルターなどにより通知せずに既読として記録した記事かどうか
+	Skipped bool `json:"skipped,omitempty"`
+
+	// MessageID は通知したDiscordのメッセージID（メッセージの編集・削除に使用する）
+	MessageID string `json:"message_id,omitempty"`
+
+	// ChannelID は通知したDiscordのチャンネルID
+	ChannelID string `json:"channel_id,omitempty"`
+
+	// WebhookID は通知に使用したDiscordのWebhookのID（Webhook URLのトークンは保存しない）
+	WebhookID string `json:"webhook_id,omitempty"`
+
+	// ContentHash は通知した時点の記事の内容のハッシュ（更新の検出に使用する）
+	ContentHash string `json:"content_hash,omitempty"`
+
+	// UpdatedAt は通知した時点の記事の更新日時（記録していない場合は nil）
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+
+	// Digest はダイジェストで通知した記事かどうか（メッセージを編集しない）
+	Digest bool `json:"digest,omitempty"`
+
+	// Retracted はフィードから削除されたため、通知済みのメッセージを削除・編集した記事かどうか
+	Retracted bool `json:"retracted,omitempty"`
+}
+
+// IsChanged は、通知した時点から記事のタイトル・説明文・更新日時が変わったかチェックする
+// 内容のハッシュを記録していない記事（この機能の導入前に通知した記事）は変更なしとして扱う
+func (n *NotifiedArticle) IsChanged(article *Article) bool {
+	if n.ContentHash == "" {
+		return false
+	}
+	if n.Title != article.Title || n.ContentHash != article.ContentHash() {
+		return true
+	}
+
+	// 更新日時はフィードが公開日時と別に提供している場合のみ比較する
+	// （日時のない記事は取得のたびに現在時刻になるため）
+	if !article.UpdatedAt.Equal(article.PublishedAt) && n.UpdatedAt != nil && !n.UpdatedAt.IsZero() {
+		return article.UpdatedAt.After(*n.UpdatedAt)
+	}
+
+	return false
+}
+
+// Update は、記事の更新後の内容を記録する
+func (n *NotifiedArticle) Update(article *Article) {
+	n.Title = article.Title
+	n.URL = article.URL
+	n.ContentHash = article.ContentHash()
+	n.UpdatedAt = optionalTime(article.UpdatedAt)
+}
+
+// optionalTime は、日時がゼロ値の場合に nil を返す（状態ファイルに記録しないため）
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// PendingDigestArticle は、ダイジェストでの通知を待っている記事を表すモデル
+// 通知先のWebhook URLは状態ファイルに保存せず、通知時にフィード設定から決定する
+type PendingDigestArticle struct {
+	// ID は記事の一意な識別子
+	ID string `json:"id"`
+
+	// Title は記事のタイトル
+	Title string `json:"title"`
+
+	// URL は記事のURL
+	URL string `json:"url"`
+
+	// FeedName は記事が属するフィード名
+	FeedName string `json:"feed_name"`
+
+	// FeedURL は記事が属するフィードのURL
+	FeedURL string `json:"feed_url"`
+
+	// Category はフィードのカテゴリ
+	Category string `json:"category"`
+
+	// PublishedAt は記事の公開日時
+	PublishedAt time.Time `json:"published_at"`
+
+	// QueuedAt はダイジェストの待ちに追加した日時
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// NewPendingDigestArticle は、記事からダイジェスト待ちの記事を作成する
+func NewPendingDigestArticle(article *Article, queuedAt time.Time) *PendingDigestArticle {
+	return &PendingDigestArticle{
+		ID:          article.ID,
+		Title:       article.Title,
+		URL:         article.URL,
+		FeedName:    article.FeedName,
+		FeedURL:     article.FeedURL,
+		Category:    article.Category,
+		PublishedAt: article.PublishedAt,
+		QueuedAt:    queuedAt,
+	}
+}
+
+// ToArticle は、ダイジェスト待ちの記事を通知用の記事に変換する
+func (p *PendingDigestArticle) ToArticle() *Article {
+	return &Article{
+		ID:          p.ID,
+		Title:       p.Title,
+		URL:         p.URL,
+		FeedName:    p.FeedName,
+		FeedURL:     p.FeedURL,
+		Category:    p.Category,
+		PublishedAt: p.PublishedAt,
+	}
 }
 
 // Statistics は、アプリケーションの統計情報を表すモデル
@@ -91,6 +232,28 @@ func (s *State) GetFeedState(feedURL string) *FeedState {
 	return feedState
 }
 
+// IsArticleQueued は、指定された記事がダイジェストの待ちに含まれているかチェックする
+func (s *State) IsArticleQueued(feedURL, articleID string) bool {
+	for _, pending := range s.DigestQueue {
+		if pending.FeedURL == feedURL && pending.ID == articleID {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveFromDigestQueue は、指定された記事をダイジェストの待ちから削除する
+func (s *State) RemoveFromDigestQueue(feedURL, articleID string) {
+	queue := make([]*PendingDigestArticle, 0, len(s.DigestQueue))
+	for _, pending := range s.DigestQueue {
+		if pending.FeedURL == feedURL && pending.ID == articleID {
+			continue
+		}
+		queue = append(queue, pending)
+	}
+	s.DigestQueue = queue
+}
+
 // IsArticleNotified は、指定された記事IDが通知済みかチェックする
 func (fs *FeedState) IsArticleNotified(articleID string) bool {
 	for _, article := range fs.NotifiedArticles {
@@ -101,6 +264,16 @@ func (fs *FeedState) IsArticleNotified(articleID string) bool {
 	return false
 }
 
+// FindArticle は、指定された記事IDの通知済み記事を返す（存在しない場合は nil）
+func (fs *FeedState) FindArticle(articleID string) *NotifiedArticle {
+	for _, article := range fs.NotifiedArticles {
+		if article.ID == articleID {
+			return article
+		}
+	}
+	return nil
+}
+
 // AddNotifiedArticle は、通知済み記事を追加する
 func (fs *FeedState) AddNotifiedArticle(article *Article) {
 	notifiedArticle := &NotifiedArticle{
@@ -109,10 +282,43 @@ func (fs *FeedState) AddNotifiedArticle(article *Article) {
 		URL:         article.URL,
 		PublishedAt: article.PublishedAt,
 		NotifiedAt:  time.Now(),
+		MessageID:   article.MessageID,
+		ChannelID:   article.ChannelID,
+		WebhookID:   article.WebhookID,
+		ContentHash: article.ContentHash(),
+		UpdatedAt:   optionalTime(article.UpdatedAt),
 	}
 
 	fs.NotifiedArticles = append(fs.NotifiedArticles, notifiedArticle)
 	fs.LastCheck = time.Now()
+	delete(fs.DeliveredRoutes, article.ID)
+}
+
+// IsRouteDelivered は、指定された記事を指定された通知先に通知済みかチェックする
+func (fs *FeedState) IsRouteDelivered(articleID, routeKey string) bool {
+	for _, key := range fs.DeliveredRoutes[articleID] {
+		if key == routeKey {
+			return true
+		}
+	}
+	return false
+}
+
+// AddDeliveredRoute は、記事を指定された通知先に通知したことを記録する
+func (fs *FeedState) AddDeliveredRoute(articleID, routeKey string) {
+	if fs.IsRouteDelivered(articleID, routeKey) {
+		return
+	}
+	if fs.DeliveredRoutes == nil {
+		fs.DeliveredRoutes = make(map[string][]string)
+	}
+	fs.DeliveredRoutes[articleID] = append(fs.DeliveredRoutes[articleID], routeKey)
+}
+
+// AddSkippedArticle は、通知せずに既読として扱う記事を追加する
+func (fs *FeedState) AddSkippedArticle(article *Article) {
+	fs.AddNotifiedArticle(article)
+	fs.NotifiedArticles[len(fs.NotifiedArticles)-1].Skipped = true
 }
 
 // CleanupOldArticles は、古い記事情報を削除する（メモリ節約）
@@ -0,0 +1,67 @@
+package models
+
+// MessageTemplate は、記事の通知メッセージのテンプレートを表すモデル
+// 各項目はGoの text/template の構文で記述し、テンプレート内では記事（Article）のフィールドを参照できる
+// 例: "{{.FeedName}}: {{.Title}}", "{{.Description | truncate 200}}"
+// 指定しない項目はデフォルトの表示（タイトル・説明文・フィード/公開日時/カテゴリのフィールドなど）になる
+type MessageTemplate struct {
+	// File はテンプレートを記述したYAMLファイルのパス（オプション、設定ファイルからの相対パス）
+	// ファイルの項目はこのテンプレートと同じ形式で、設定ファイルに直接指定した項目が優先される
+	File string `yaml:"file,omitempty"`
+
+	// Content はEmbedの外に表示するメッセージ本文（メンションの後に続く）
+	Content string `yaml:"content,omitempty"`
+
+	// Title はEmbedのタイトル
+	Title string `yaml:"title,omitempty"`
+
+	// Description はEmbedの説明文
+	Description string `yaml:"description,omitempty"`
+
+	// Fields はEmbedのフィールド（指定した場合はデフォルトのフィールドを置き換える）
+	// 値が空になったフィールドは表示しない
+	Fields []*TemplateField `yaml:"fields,omitempty"`
+
+	// Footer はEmbedのフッターのテキスト
+	Footer string `yaml:"footer,omitempty"`
+
+	// Author はEmbedの著者名
+	Author string `yaml:"author,omitempty"`
+}
+
+// TemplateField は、テンプレートで定義するEmbedのフィールドを表すモデル
+type TemplateField struct {
+	// Name はフィールド名のテンプレート
+	Name string `yaml:"name"`
+
+	// Value はフィールドの値のテンプレート
+	Value string `yaml:"value"`
+
+	// Inline はフィールドを横に並べて表示するかどうか
+	Inline bool `yaml:"inline,omitempty"`
+}
+
+// Merge は、base の項目のうち、このテンプレートで指定していない項目を引き継ぐ
+func (t *MessageTemplate) Merge(base *MessageTemplate) {
+	if base == nil {
+		return
+	}
+	if t.Content == "" {
+		t.Content = base.Content
+	}
+	if t.Title == "" {
+		t.Title = base.Title
+	}
+	if t.Description == "" {
+		t.Description = base.Description
+	}
+	if t.Fields == nil {
+		t.Fields = base.Fields
+	}
+	if t.Footer == "" {
+		t.Footer = base.Footer
+	}
+	if t.Author == "" {
+		t.Author = base.Author
+	}
+}